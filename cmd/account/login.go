@@ -10,12 +10,13 @@ import (
 )
 
 func LoginCommand() *cobra.Command {
+	var noBrowserFlag bool
 	command := &cobra.Command{
 		Use:   "login",
 		Short: "Login to your Shopify account and authorize this app",
 		Long:  ``,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			authModel := tui.CreateAuthentication()
+			authModel := tui.CreateAuthentication(noBrowserFlag)
 
 			if _, err := tea.NewProgram(&authModel).Run(); err != nil {
 				fmt.Println("could not run program:", err)
@@ -25,5 +26,8 @@ func LoginCommand() *cobra.Command {
 			return nil
 		},
 	}
+
+	command.Flags().BoolVar(&noBrowserFlag, "no-browser", false, "Print the authorization URL and paste the URL you are redirected to, for sessions without a browser")
+
 	return command
 }
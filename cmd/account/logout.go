@@ -2,16 +2,18 @@ package account
 
 import (
 	"github.com/CarlosGMI/Playlistify/services"
+	"github.com/CarlosGMI/Playlistify/utils"
 	"github.com/spf13/cobra"
 )
 
 func LogoutCommand() *cobra.Command {
 	command := &cobra.Command{
-		Use:   "logout",
-		Short: "Log out from your current Spotify account",
-		Long:  ``,
-		Run: func(cmd *cobra.Command, args []string) {
-			services.EmptyTokenInformation()
+		Use:         "logout",
+		Annotations: map[string]string{utils.OfflineAnnotation: "true"},
+		Short:       "Log out from your current Spotify account",
+		Long:        ``,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return services.EmptyTokenInformation()
 		},
 	}
 
@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"fmt"
+
+	"github.com/CarlosGMI/Playlistify/services"
+	"github.com/CarlosGMI/Playlistify/tui"
+	"github.com/CarlosGMI/Playlistify/utils"
+	"github.com/spf13/cobra"
+)
+
+func CacheCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:         "cache",
+		Annotations: map[string]string{utils.OfflineAnnotation: "true"},
+		Short:       "Manage the local cache of playlist tracks",
+		Long: `The tracks of every playlist you search in are cached locally and downloaded again only when the playlist changes.
+		Use the --refresh flag in any command to ignore the cache.`,
+	}
+
+	command.AddCommand(clearCommand())
+	command.AddCommand(statsCommand())
+
+	return command
+}
+
+func clearCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "clear",
+		Short: "Remove all the cached tracks",
+		Long:  ``,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := services.ClearTracksCache(); err != nil {
+				utils.ExitWithError(err, utils.ExitCodeError)
+			}
+
+			fmt.Println("The cache was cleared")
+		},
+	}
+
+	return command
+}
+
+func statsCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "stats",
+		Short: "Show the cached playlists and the size of the cache",
+		Long:  ``,
+		Run: func(cmd *cobra.Command, args []string) {
+			output := utils.Options.Output
+			stats, err := services.GetTracksCacheStats()
+
+			if err != nil {
+				utils.ExitWithError(err, utils.ExitCodeError)
+			}
+
+			if output == "" {
+				output = utils.OutputTable
+				fmt.Println(stats.Title)
+			}
+
+			if err := tui.PrintOutput(output, stats.TableType, stats.TextResults); err != nil {
+				utils.ExitWithError(err, utils.ExitCodeError)
+			}
+		},
+	}
+
+	return command
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/CarlosGMI/Playlistify/services"
+	"github.com/CarlosGMI/Playlistify/utils"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// unlockCredentials loads the credentials of the commands that use the Spotify API, the ones annotated as offline
+// (or whose parent is) don't need them
+func unlockCredentials(cmd *cobra.Command) error {
+	for parent := cmd; parent != nil; parent = parent.Parent() {
+		if parent.Annotations[utils.OfflineAnnotation] == "true" {
+			return nil
+		}
+	}
+
+	if cmd.Name() == "help" || cmd.Name() == "completion" || !cmd.HasParent() {
+		return nil
+	}
+
+	return services.UnlockCredentials(promptPassphrase, cmd.Name() == "login")
+}
+
+// promptPassphrase reads the passphrase of the encrypted credentials file from the terminal, asking twice for a new one
+func promptPassphrase(create bool) (string, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", errors.New(utils.MissingPassphraseError)
+	}
+
+	prompt := "Passphrase of the Playlistify credentials: "
+
+	if create {
+		prompt = "Choose a passphrase to encrypt the Playlistify credentials: "
+	}
+
+	passphrase, err := readPassphrase(prompt)
+
+	if err != nil {
+		return "", err
+	}
+
+	if passphrase == "" {
+		return "", errors.New(utils.EmptyPassphraseError)
+	}
+
+	if !create {
+		return passphrase, nil
+	}
+
+	confirmation, err := readPassphrase("Repeat the passphrase: ")
+
+	if err != nil {
+		return "", err
+	}
+
+	if confirmation != passphrase {
+		return "", errors.New(utils.PassphraseMismatchError)
+	}
+
+	return passphrase, nil
+}
+
+// readPassphrase writes the prompt to the standard error so it doesn't mix with the results of --output
+func readPassphrase(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+
+	return string(passphrase), err
+}
@@ -0,0 +1,48 @@
+package dev
+
+import (
+	"github.com/CarlosGMI/Playlistify/services/fakeserver"
+	"github.com/CarlosGMI/Playlistify/utils"
+	"github.com/spf13/cobra"
+)
+
+func DevCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:         "dev",
+		Annotations: map[string]string{utils.OfflineAnnotation: "true"},
+		Short:       "Tools for the development of Playlistify",
+		Long:        ``,
+	}
+
+	command.AddCommand(fakeServerCommand())
+
+	return command
+}
+
+func fakeServerCommand() *cobra.Command {
+	var options fakeserver.Options
+	command := &cobra.Command{
+		Use:   "fake-server",
+		Short: "Serve a fake Spotify API from a fixture directory",
+		Long: `This command serves the Spotify endpoints used by Playlistify (/me, /me/playlists, /playlists/{id}, /playlists/{id}/tracks, /authorize and /api/token) so the login, list and search flows can run without Spotify.
+
+		The fixture directory must contain a me.json file with the user, a playlists.json file with the list of playlists and a tracks/{playlist id}.json file with the items of every playlist. A sample set of fixtures is used when --fixtures isn't set.
+
+		Usage:
+		- playlistify dev fake-server [--fixtures DIRECTORY] [--port PORT]
+		Example:
+		  - playlistify dev fake-server
+		  - playlistify dev fake-server --fixtures ./fixtures --rate-limit-every 5 --token-ttl 30`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return fakeserver.Run(options)
+		},
+	}
+
+	command.Flags().StringVar(&options.Fixtures, "fixtures", "", "Directory with the fixtures (defaults to the embedded sample fixtures)")
+	command.Flags().IntVar(&options.Port, "port", 8888, "Port to listen on")
+	command.Flags().IntVar(&options.RateLimitEvery, "rate-limit-every", 0, "Answer every nth API request with a 429 (0 disables it)")
+	command.Flags().IntVar(&options.RetryAfter, "retry-after", 1, "Value of the Retry-After header of the 429 responses")
+	command.Flags().IntVar(&options.TokenTTL, "token-ttl", 3600, "Seconds until the access tokens expire")
+
+	return command
+}
@@ -0,0 +1,53 @@
+package playlist
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/CarlosGMI/Playlistify/services"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+func AddCommand() *cobra.Command {
+	var playlistIdFlag string
+	var positionFlag int
+	var forceFlag bool
+	var yesFlag bool
+	command := &cobra.Command{
+		Use:   "add -p PLAYLIST TRACK...",
+		Short: "Add tracks to a playlist, skipping the ones that are already in it",
+		Long: `This command adds tracks to a playlist. Every track can be a Spotify track URI, an open.spotify.com/track URL or an "Artist - Title" text, which is searched in Spotify.
+
+		Before adding them, the tracks are compared with the ones of the playlist using the criteria of the dupes command: a track is a duplicate when it's the same track, it has the same ISRC or it has a similar name (ignoring decorations like "(Live)" or "- Remastered") and the same main artist. The fuzzy matching of the search command isn't used, so tracks that only share some words with the ones of the playlist are added. The ones that look like duplicates are skipped unless you use --force. The changes are shown for confirmation and recorded so they can be undone.
+
+		Usage:
+		- playlistify add -p PLAYLIST TRACK...
+		Example:
+		  - playlistify add -p 10 spotify:track:4uLU6hMCjMI75M1A2tKUQC
+		  - playlistify add -p "road trip" "Linkin Park - Numb" "Queen - Under Pressure"
+		  - playlistify add -p 10 https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC --position 1
+		  - playlistify add -p 10 "Daft Punk - One More Time" --force --yes --output json`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			addTracks := confirmChanges(func() tea.Msg {
+				return services.AddTracksToPlaylist(playlistIdFlag, args, positionFlag, forceFlag)
+			}, yesFlag)
+
+			if err := runResultsModel("Looking for the tracks...", addTracks); err != nil {
+				fmt.Println("could not run program:", err)
+				os.Exit(1)
+			}
+
+			return nil
+		},
+	}
+
+	command.Flags().StringVarP(&playlistIdFlag, "playlist", "p", "", "Playlist index, name, Spotify ID, URI or URL (required)")
+	command.Flags().IntVar(&positionFlag, "position", 0, "Position (starting at 1) where the tracks are inserted, they are appended by default")
+	command.Flags().BoolVar(&forceFlag, "force", false, "Add the tracks even if they look like duplicates")
+	command.Flags().BoolVarP(&yesFlag, "yes", "y", false, "Add the tracks without asking for confirmation")
+	command.MarkFlagRequired("playlist")
+
+	return command
+}
@@ -0,0 +1,47 @@
+package playlist
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/CarlosGMI/Playlistify/services"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+func CheckCommand() *cobra.Command {
+	var playlistIdFlag string
+	var fromFlag string
+	command := &cobra.Command{
+		Use:   "check",
+		Short: "Check which tracks of a list are already in a playlist",
+		Long: `This command is a batch version of search: it looks for every entry of a file in the playlist and reports if it was found, not found or if it matched several tracks.
+
+		The file can be a CSV with title and artist columns, an M3U playlist or a text file with an "Artist - Title" entry per line.
+
+		Usage:
+		- playlistify check -p PLAYLIST --from FILE
+		Example:
+		  - playlistify check -p 10 --from requests.csv
+		  - playlistify check -p "road trip" --from requests.txt --output csv`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			checkTrackList := func() tea.Msg {
+				return services.CheckTrackListInPlaylist(playlistIdFlag, fromFlag)
+			}
+
+			if err := runResultsModel("Checking tracks...", checkTrackList); err != nil {
+				fmt.Println("could not run program:", err)
+				os.Exit(1)
+			}
+
+			return nil
+		},
+	}
+
+	command.Flags().StringVarP(&playlistIdFlag, "playlist", "p", "", "Playlist index, name, Spotify ID, URI or URL (required)")
+	command.Flags().StringVar(&fromFlag, "from", "", "CSV, M3U or text file with the tracks to check (required)")
+	command.MarkFlagRequired("playlist")
+	command.MarkFlagRequired("from")
+
+	return command
+}
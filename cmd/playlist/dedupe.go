@@ -0,0 +1,51 @@
+package playlist
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/CarlosGMI/Playlistify/services"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+func DedupeCommand() *cobra.Command {
+	var playlistIdFlag string
+	var similarFlag bool
+	var dryRunFlag bool
+	var yesFlag bool
+	command := &cobra.Command{
+		Use:   "dedupe",
+		Short: "Remove the duplicate tracks of a playlist",
+		Long: `This command removes every occurrence of a duplicate track except the first one. By default it's a dry run that only shows the occurrences that would be kept and removed, use --dry-run=false to remove them after confirming the changes.
+
+		Tracks with a similar name and artist are only removed when using --similar, since they may be different versions of the track. Every removal is recorded so it can be undone.
+
+		Usage:
+		- playlistify dedupe -p PLAYLIST
+		Example:
+		  - playlistify dedupe -p 10
+		  - playlistify dedupe -p "road trip" --dry-run=false
+		  - playlistify dedupe -p 10 --dry-run=false --yes --output json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			removeDuplicates := confirmChanges(func() tea.Msg {
+				return services.RemoveDuplicatesFromPlaylist(playlistIdFlag, similarFlag, dryRunFlag)
+			}, yesFlag)
+
+			if err := runResultsModel("Looking for duplicates...", removeDuplicates); err != nil {
+				fmt.Println("could not run program:", err)
+				os.Exit(1)
+			}
+
+			return nil
+		},
+	}
+
+	command.Flags().StringVarP(&playlistIdFlag, "playlist", "p", "", "Playlist index, name, Spotify ID, URI or URL (required)")
+	command.Flags().BoolVar(&similarFlag, "similar", false, "Also remove tracks of the same artist with similar names")
+	command.Flags().BoolVar(&dryRunFlag, "dry-run", true, "Only show the duplicates that would be removed")
+	command.Flags().BoolVarP(&yesFlag, "yes", "y", false, "Remove the duplicates without asking for confirmation")
+	command.MarkFlagRequired("playlist")
+
+	return command
+}
@@ -0,0 +1,47 @@
+package playlist
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/CarlosGMI/Playlistify/services"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+func DiffCommand() *cobra.Command {
+	var firstPlaylistFlag string
+	var secondPlaylistFlag string
+	command := &cobra.Command{
+		Use:   "diff",
+		Short: "Compare the tracks of two playlists",
+		Long: `This command shows the tracks that are only in the playlist A, only in the playlist B and in both of them.
+
+		The tracks are matched by their Spotify ID and, when that fails, by their ISRC or a similar name and artist, so re-uploaded versions of a track are also matched.
+
+		Usage:
+		- playlistify diff -a PLAYLIST -b PLAYLIST
+		Example:
+		  - playlistify diff -a 10 -b 11
+		  - playlistify diff --first "road trip staging" --second "road trip" --output json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			comparePlaylists := func() tea.Msg {
+				return services.ComparePlaylists(firstPlaylistFlag, secondPlaylistFlag)
+			}
+
+			if err := runResultsModel("Comparing playlists...", comparePlaylists); err != nil {
+				fmt.Println("could not run program:", err)
+				os.Exit(1)
+			}
+
+			return nil
+		},
+	}
+
+	command.Flags().StringVarP(&firstPlaylistFlag, "first", "a", "", "First playlist index, name, Spotify ID, URI or URL (required)")
+	command.Flags().StringVarP(&secondPlaylistFlag, "second", "b", "", "Second playlist index, name, Spotify ID, URI or URL (required)")
+	command.MarkFlagRequired("first")
+	command.MarkFlagRequired("second")
+
+	return command
+}
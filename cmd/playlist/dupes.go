@@ -0,0 +1,45 @@
+package playlist
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/CarlosGMI/Playlistify/services"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+func DupesCommand() *cobra.Command {
+	var playlistIdFlag string
+	var similarFlag bool
+	command := &cobra.Command{
+		Use:   "dupes",
+		Short: "Find all the duplicate tracks inside a playlist",
+		Long: `This command groups the tracks of a playlist that are duplicates because they are the same track, they share the same ISRC or they have a similar name and artist.
+
+		Usage:
+		- playlistify dupes -p PLAYLIST
+		Example:
+		  - playlistify dupes -p 10
+		  - playlistify dupes -p "road trip" --similar=false
+		  - playlistify dupes -p 10 --output json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			findDuplicates := func() tea.Msg {
+				return services.FindDuplicatesInPlaylist(playlistIdFlag, similarFlag)
+			}
+
+			if err := runResultsModel("Looking for duplicates...", findDuplicates); err != nil {
+				fmt.Println("could not run program:", err)
+				os.Exit(1)
+			}
+
+			return nil
+		},
+	}
+
+	command.Flags().StringVarP(&playlistIdFlag, "playlist", "p", "", "Playlist index, name, Spotify ID, URI or URL (required)")
+	command.Flags().BoolVar(&similarFlag, "similar", true, "Also group tracks of the same artist with similar names")
+	command.MarkFlagRequired("playlist")
+
+	return command
+}
@@ -0,0 +1,56 @@
+package playlist
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/CarlosGMI/Playlistify/services"
+	"github.com/CarlosGMI/Playlistify/utils"
+	"github.com/spf13/cobra"
+)
+
+func ExportCommand() *cobra.Command {
+	var playlistIdFlag string
+	var formatFlag string
+	var fileFlag string
+	command := &cobra.Command{
+		Use:   "export",
+		Short: "Export all the tracks of a playlist to CSV, JSON, M3U8 or XSPF",
+		Long: `This command writes every track of a playlist with its position, name, artists, album, duration, ISRC, Spotify URI and the date it was added.
+
+		Usage:
+		- playlistify export -p PLAYLIST --format csv|json|m3u8|xspf [--file FILE]
+		Example:
+		  - playlistify export -p 10 > backup.csv
+		  - playlistify export -p "road trip" --format json --file road-trip.json
+		  - playlistify export -p spotify:playlist:37i9dQZF1DXcBWIGoYBM5M --format xspf --file road-trip.xspf`,
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+
+			if !services.IsExportFormat(formatFlag) {
+				utils.ExitWithError(fmt.Errorf(utils.InvalidExportFormatError, formatFlag), utils.ExitCodeError)
+			}
+
+			if err := services.CheckAuthentication(); err != nil {
+				utils.ExitWithError(err, utils.ExitCodeNotLoggedIn)
+			}
+
+			if fileFlag != "" {
+				err = services.ExportPlaylistToFile(playlistIdFlag, formatFlag, fileFlag)
+			} else {
+				err = services.ExportPlaylist(playlistIdFlag, formatFlag, os.Stdout)
+			}
+
+			if err != nil {
+				utils.ExitWithError(err, utils.ExitCodeError)
+			}
+		},
+	}
+
+	command.Flags().StringVarP(&playlistIdFlag, "playlist", "p", "", "Playlist index, name, Spotify ID, URI or URL (required)")
+	command.Flags().StringVarP(&formatFlag, "format", "f", utils.ExportCSV, "Export format: csv, json, m3u8 or xspf")
+	command.Flags().StringVar(&fileFlag, "file", "", "File to write to instead of the standard output")
+	command.MarkFlagRequired("playlist")
+
+	return command
+}
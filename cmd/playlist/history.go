@@ -0,0 +1,38 @@
+package playlist
+
+import (
+	"fmt"
+
+	"github.com/CarlosGMI/Playlistify/services"
+	"github.com/CarlosGMI/Playlistify/tui"
+	"github.com/CarlosGMI/Playlistify/utils"
+	"github.com/spf13/cobra"
+)
+
+func HistoryCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:         "history",
+		Annotations: map[string]string{utils.OfflineAnnotation: "true"},
+		Short:       "List the operations that modified your playlists",
+		Long:        `This command lists the operations recorded in the journal, starting with the most recent one. Use their IDs to undo them with "playlistify undo".`,
+		Run: func(cmd *cobra.Command, args []string) {
+			output := utils.Options.Output
+			history, err := services.GetHistory()
+
+			if err != nil {
+				utils.ExitWithError(err, utils.ExitCodeError)
+			}
+
+			if output == "" {
+				output = utils.OutputTable
+				fmt.Println(history.Title)
+			}
+
+			if err := tui.PrintOutput(output, history.TableType, history.TextResults); err != nil {
+				utils.ExitWithError(err, utils.ExitCodeError)
+			}
+		},
+	}
+
+	return command
+}
@@ -1,10 +1,13 @@
 package playlist
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
+	"github.com/CarlosGMI/Playlistify/services"
 	"github.com/CarlosGMI/Playlistify/tui"
+	"github.com/CarlosGMI/Playlistify/utils"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 )
@@ -15,6 +18,12 @@ func ListCommand() *cobra.Command {
 		Short: "List all your Spotify playlists, including collaborative playlists",
 		Long:  ``,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if output := utils.Options.Output; output != "" {
+				printPlaylists(output)
+
+				return nil
+			}
+
 			model := tui.CreatePlaylistsModel()
 
 			if _, err := tea.NewProgram(&model).Run(); err != nil {
@@ -28,3 +37,23 @@ func ListCommand() *cobra.Command {
 
 	return command
 }
+
+func printPlaylists(output string) {
+	if err := services.CheckAuthentication(); err != nil {
+		utils.ExitWithError(err, utils.ExitCodeNotLoggedIn)
+	}
+
+	if msg, ok := services.GetPlaylists().(services.PlaylistsErrorMsg); ok {
+		utils.ExitWithError(errors.New(msg.Message), utils.ExitCodeError)
+	}
+
+	_, textPlaylists, err := services.PrintPlaylists()
+
+	if err != nil {
+		utils.ExitWithError(err, utils.ExitCodeError)
+	}
+
+	if err := tui.PrintOutput(output, utils.PlaylistsTable, textPlaylists); err != nil {
+		utils.ExitWithError(err, utils.ExitCodeError)
+	}
+}
@@ -0,0 +1,54 @@
+package playlist
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/CarlosGMI/Playlistify/services"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+func MergeCommand() *cobra.Command {
+	var fromFlags []string
+	var intoFlag string
+	var newFlag bool
+	var similarFlag bool
+	var yesFlag bool
+	command := &cobra.Command{
+		Use:   "merge",
+		Short: "Merge several playlists into one, skipping the duplicates",
+		Long: `This command appends the tracks of the source playlists, in order, to the target playlist. The tracks that are already in the target or in a previous source (the same track, the same ISRC or a similar name and artist) are skipped, and the summary tells why.
+
+		The target can be an existing playlist or, with --new, the name of a new private playlist. The changes are shown for confirmation and recorded so they can be undone.
+
+		Usage:
+		- playlistify merge --from PLAYLIST --from PLAYLIST --into PLAYLIST
+		Example:
+		  - playlistify merge --from 10 --from 11 --into 12
+		  - playlistify merge --from "road trip" --from "road trip 2" --into "Road Trip (all)" --new
+		  - playlistify merge --from 10 --from 11 --into 12 --similar=false --yes --output json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mergePlaylists := confirmChanges(func() tea.Msg {
+				return services.MergePlaylists(fromFlags, intoFlag, newFlag, similarFlag)
+			}, yesFlag)
+
+			if err := runResultsModel("Comparing the playlists...", mergePlaylists); err != nil {
+				fmt.Println("could not run program:", err)
+				os.Exit(1)
+			}
+
+			return nil
+		},
+	}
+
+	command.Flags().StringArrayVar(&fromFlags, "from", nil, "Source playlist index, name, Spotify ID, URI or URL, can be repeated (required)")
+	command.Flags().StringVar(&intoFlag, "into", "", "Target playlist index, name, Spotify ID, URI or URL, or the name of the new playlist with --new (required)")
+	command.Flags().BoolVar(&newFlag, "new", false, "Create a new private playlist named after --into")
+	command.Flags().BoolVar(&similarFlag, "similar", true, "Also skip tracks of the same artist with similar names")
+	command.Flags().BoolVarP(&yesFlag, "yes", "y", false, "Merge the playlists without asking for confirmation")
+	command.MarkFlagRequired("from")
+	command.MarkFlagRequired("into")
+
+	return command
+}
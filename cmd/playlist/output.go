@@ -0,0 +1,59 @@
+package playlist
+
+import (
+	"errors"
+
+	"github.com/CarlosGMI/Playlistify/services"
+	"github.com/CarlosGMI/Playlistify/tui"
+	"github.com/CarlosGMI/Playlistify/utils"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// printTableResults runs a command without the TUI and prints its results using the --output format
+func printTableResults(output string, command tea.Cmd) {
+	if err := services.CheckAuthentication(); err != nil {
+		utils.ExitWithError(err, utils.ExitCodeNotLoggedIn)
+	}
+
+	switch msg := command().(type) {
+	case services.PlaylistsErrorMsg:
+		utils.ExitWithError(errors.New(msg.Message), utils.ExitCodeError)
+	case services.AmbiguousPlaylistMsg:
+		utils.ExitWithError(errors.New(msg.Message), utils.ExitCodeError)
+	case services.ConfirmationMsg:
+		utils.ExitWithError(errors.New(utils.ConfirmationRequiredError), utils.ExitCodeError)
+	case services.TableResultsMsg:
+		if err := tui.PrintOutput(output, msg.TableType, msg.TextResults); err != nil {
+			utils.ExitWithError(err, utils.ExitCodeError)
+		}
+	}
+}
+
+// confirmChanges applies the changes of a ConfirmationMsg returned by the command right away when the --yes flag is
+// used, otherwise the TUI asks for confirmation (and --output refuses to make them)
+func confirmChanges(command tea.Cmd, yes bool) tea.Cmd {
+	return func() tea.Msg {
+		msg := command()
+
+		if confirmation, ok := msg.(services.ConfirmationMsg); ok && yes {
+			return confirmation.Action()
+		}
+
+		return msg
+	}
+}
+
+// runResultsModel runs a command showing its results in the TUI, or prints them when the --output flag is used
+func runResultsModel(loaderText string, command tea.Cmd) error {
+	if output := utils.Options.Output; output != "" {
+		printTableResults(output, command)
+
+		return nil
+	}
+
+	model := tui.CreateResultsModel(loaderText, command)
+
+	_, err := tea.NewProgram(model, tea.WithMouseCellMotion()).Run()
+
+	return err
+}
@@ -1,10 +1,13 @@
 package playlist
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
+	"github.com/CarlosGMI/Playlistify/services"
 	"github.com/CarlosGMI/Playlistify/tui"
+	"github.com/CarlosGMI/Playlistify/utils"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 )
@@ -12,24 +15,55 @@ import (
 func SearchCommand() *cobra.Command {
 	var playlistIdFlag string
 	var searchTermFlag string
+	var allPlaylistsFlag bool
+	var saveAsFlag string
 	command := &cobra.Command{
 		Use:   "search",
 		Short: "Command to look for a song/artist inside a specific playlist",
 		Long: `This command will allow you to find possible duplicates in a playlist by looking up a specific term within all the tracks of the playlist.
 
+		The playlist can be the index shown by "playlistify list", a Spotify playlist ID, a spotify:playlist: URI, an open.spotify.com/playlist URL or the name of the playlist.
+
 		Usage:
-		- playlistify search -p PLAYLIST_ID -s "TERM_YOU_WANT_TO_LOOK_FOR"
-		- playlistify search -p PLAYLIST_ID -s "TERM_YOU_WANT_TO_LOOK_FOR"
+		- playlistify search -p PLAYLIST -t "TERM_YOU_WANT_TO_LOOK_FOR"
+		- playlistify search --all -t "TERM_YOU_WANT_TO_LOOK_FOR"
 		Example:
 		  - playlistify search
-		  - playlistify search -p 10 -s "Linkin"
-		  - playlistify search -p 2 -s "two hearts"`,
+		  - playlistify search -p 10 -t "Linkin"
+		  - playlistify search -p 2 -t "two hearts"
+		  - playlistify search -p "road trip" -t "two hearts"
+		  - playlistify search -p spotify:playlist:37i9dQZF1DXcBWIGoYBM5M -t "two hearts"
+		  - playlistify search -p https://open.spotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M -t "two hearts"
+		  - playlistify search --all -t "Linkin"
+		  - playlistify search --all -t "Linkin" --save-as "Everything Linkin Park"
+
+		The matched tracks can be saved as a new private playlist with --save-as, or by pressing "c" in the results table.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var model tui.SearchModel
 			hasPlaylist := cmd.Flags().Changed("playlist")
 			hasSearchTerm := cmd.Flags().Changed("term")
 
-			if !hasPlaylist && !hasSearchTerm {
+			if !allPlaylistsFlag && hasPlaylist != hasSearchTerm {
+				return errors.New(utils.IncompleteSearchFlagsError)
+			}
+
+			if output := utils.Options.Output; output != "" || saveAsFlag != "" {
+				if output != "" && ((!hasPlaylist && !allPlaylistsFlag) || !hasSearchTerm) {
+					utils.ExitWithError(errors.New(utils.MissingSearchFlagsError), utils.ExitCodeError)
+				}
+
+				if (!hasPlaylist && !allPlaylistsFlag) || !hasSearchTerm {
+					utils.ExitWithError(errors.New(utils.MissingSaveAsFlagsError), utils.ExitCodeError)
+				}
+
+				printSearchResults(output, playlistIdFlag, searchTermFlag, allPlaylistsFlag, saveAsFlag)
+
+				return nil
+			}
+
+			if allPlaylistsFlag {
+				model = tui.CreateSearchAllModel(searchTermFlag)
+			} else if !hasPlaylist && !hasSearchTerm {
 				model = tui.CreateSearchModel(true, "", "")
 			} else {
 				model = tui.CreateSearchModel(false, playlistIdFlag, searchTermFlag)
@@ -44,9 +78,69 @@ func SearchCommand() *cobra.Command {
 		},
 	}
 
-	command.Flags().StringVarP(&playlistIdFlag, "playlist", "p", "", "Playlist ID (required)")
+	command.Flags().StringVarP(&playlistIdFlag, "playlist", "p", "", "Playlist index, name, Spotify ID, URI or URL (required)")
 	command.Flags().StringVarP(&searchTermFlag, "term", "t", "", "Term to search for (required)")
-	command.MarkFlagsRequiredTogether("playlist", "term")
+	command.Flags().BoolVarP(&allPlaylistsFlag, "all", "a", false, "Search in all your playlists instead of a single one")
+	command.Flags().StringVar(&saveAsFlag, "save-as", "", "Save the matched tracks as a new private playlist with this name")
+	command.MarkFlagsMutuallyExclusive("playlist", "all")
 
 	return command
 }
+
+// printSearchResults runs the search without the TUI, printing its results when there's an output format and
+// saving them as a new playlist when there's a name for it
+func printSearchResults(output string, playlistId string, searchTerm string, allPlaylists bool, saveAs string) {
+	var result tea.Msg
+	var tableType = utils.SongsTable
+
+	if err := services.CheckAuthentication(); err != nil {
+		utils.ExitWithError(err, utils.ExitCodeNotLoggedIn)
+	}
+
+	if allPlaylists {
+		tableType = utils.AllSongsTable
+		result = services.SearchInAllPlaylists(searchTerm)
+	} else {
+		result = services.SearchInPlaylist(playlistId, searchTerm)
+	}
+
+	switch msg := result.(type) {
+	case services.PlaylistsErrorMsg:
+		utils.ExitWithError(errors.New(msg.Message), utils.ExitCodeError)
+	case services.AmbiguousPlaylistMsg:
+		utils.ExitWithError(errors.New(msg.Message), utils.ExitCodeError)
+	case services.SearchResultsMsg:
+		if msg.Status == utils.SearchFailed {
+			utils.ExitWithError(fmt.Errorf(utils.SearchFailedError, msg.Error), utils.ExitCodeError)
+		}
+
+		if output != "" {
+			if err := tui.PrintOutput(output, tableType, msg.TextResults); err != nil {
+				utils.ExitWithError(err, utils.ExitCodeError)
+			}
+		}
+
+		if saveAs != "" {
+			saveSearchResults(saveAs, msg, output != "")
+		}
+
+		if msg.Status == utils.SearchPartial {
+			utils.ExitWithError(errors.New(msg.Warning()), utils.ExitCodePartialResults)
+		}
+	}
+}
+
+// saveSearchResults prints the result of saving the search as a new playlist, to stderr when the results are
+// printed so it doesn't mix with them
+func saveSearchResults(name string, results services.SearchResultsMsg, printedResults bool) {
+	switch msg := services.SaveSearchResults(name, results).(type) {
+	case services.PlaylistsErrorMsg:
+		utils.ExitWithError(errors.New(msg.Message), utils.ExitCodeError)
+	case services.PlaylistSavedMsg:
+		if printedResults {
+			fmt.Fprintln(os.Stderr, msg.Message)
+		} else {
+			fmt.Println(msg.Message)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package playlist
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/CarlosGMI/Playlistify/services"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+func UndoCommand() *cobra.Command {
+	var forceFlag bool
+	var yesFlag bool
+	command := &cobra.Command{
+		Use:   "undo [operation ID]",
+		Short: "Undo an operation that modified a playlist",
+		Long: `This command reverts an operation of the history (like the removal of duplicates) restoring the removed tracks at their positions and removing the added ones. Without an operation ID it undoes the last operation that wasn't undone.
+
+		The playlist can't have changed after the operation, since the positions of its tracks wouldn't be valid anymore. Use --force to undo it anyway.
+
+		Usage:
+		- playlistify undo [OPERATION ID]
+		Example:
+		  - playlistify undo
+		  - playlistify undo 20240301-184502
+		  - playlistify undo 20240301-184502 --yes --output json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var operationId string
+
+			if len(args) > 0 {
+				operationId = args[0]
+			}
+
+			undoOperation := confirmChanges(func() tea.Msg {
+				return services.UndoOperation(operationId, forceFlag)
+			}, yesFlag)
+
+			if err := runResultsModel("Looking for the operation...", undoOperation); err != nil {
+				fmt.Println("could not run program:", err)
+				os.Exit(1)
+			}
+
+			return nil
+		},
+	}
+
+	command.Flags().BoolVar(&forceFlag, "force", false, "Undo the operation even if the playlist changed after it")
+	command.Flags().BoolVarP(&yesFlag, "yes", "y", false, "Undo the operation without asking for confirmation")
+
+	return command
+}
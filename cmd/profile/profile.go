@@ -0,0 +1,93 @@
+package profile
+
+import (
+	"fmt"
+
+	"github.com/CarlosGMI/Playlistify/services"
+	"github.com/CarlosGMI/Playlistify/tui"
+	"github.com/CarlosGMI/Playlistify/utils"
+	"github.com/spf13/cobra"
+)
+
+func ProfileCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:         "profile",
+		Annotations: map[string]string{utils.OfflineAnnotation: "true"},
+		Short:       "Manage the profiles of your Spotify accounts",
+		Long: `Every profile has its own Spotify account, playlists, cached tracks and history. The commands use the active profile, which can be changed for a single command with the --profile flag.
+
+		A profile is created the first time you log in with it.
+
+		Usage:
+		- playlistify profile list|use|remove
+		Example:
+		  - playlistify login --profile curator
+		  - playlistify profile use curator
+		  - playlistify list --profile default`,
+	}
+
+	command.AddCommand(listCommand())
+	command.AddCommand(useCommand())
+	command.AddCommand(removeCommand())
+
+	return command
+}
+
+func listCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "list",
+		Short: "List the profiles",
+		Long:  ``,
+		Run: func(cmd *cobra.Command, args []string) {
+			output := utils.Options.Output
+			profiles := services.GetProfiles()
+
+			if output == "" {
+				output = utils.OutputTable
+				fmt.Println(profiles.Title)
+			}
+
+			if err := tui.PrintOutput(output, profiles.TableType, profiles.TextResults); err != nil {
+				utils.ExitWithError(err, utils.ExitCodeError)
+			}
+		},
+	}
+
+	return command
+}
+
+func useCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "use [profile]",
+		Short: "Make the profile the active one",
+		Long:  ``,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := services.UseProfile(args[0]); err != nil {
+				utils.ExitWithError(err, utils.ExitCodeError)
+			}
+
+			fmt.Printf("The active profile is %s\n", args[0])
+		},
+	}
+
+	return command
+}
+
+func removeCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "remove [profile]",
+		Short: "Remove the profile with its credentials, cached tracks and history",
+		Long:  ``,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := services.RemoveProfile(args[0]); err != nil {
+				utils.ExitWithError(err, utils.ExitCodeError)
+			}
+
+			fmt.Printf("The profile %s was removed\n", args[0])
+		},
+	}
+
+	return command
+}
@@ -1,10 +1,19 @@
 package cmd
 
 import (
+	"fmt"
+	"io"
+	"log"
 	"os"
+	"path/filepath"
 
 	"github.com/CarlosGMI/Playlistify/cmd/account"
+	"github.com/CarlosGMI/Playlistify/cmd/cache"
+	"github.com/CarlosGMI/Playlistify/cmd/dev"
 	"github.com/CarlosGMI/Playlistify/cmd/playlist"
+	"github.com/CarlosGMI/Playlistify/cmd/profile"
+	"github.com/CarlosGMI/Playlistify/utils"
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -14,18 +23,38 @@ var rootCmd = &cobra.Command{
 	Use:   "playlistify",
 	Short: "CLI application to look for a song or artist inside a specific Spotify playlist",
 	Long:  ``,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateOutput(); err != nil {
+			return err
+		}
+
+		if err := utils.ValidateProfileName(utils.ActiveProfile()); err != nil {
+			return err
+		}
+
+		if err := unlockCredentials(cmd); err != nil {
+			// The flags are right, so the usage of the command wouldn't help
+			cmd.SilenceUsage = true
+
+			return utils.NewCommandError(err, utils.ExitCodeError)
+		}
+
+		return nil
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// Cobra already printed the error, so only the exit code of the error is left
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		os.Exit(utils.GetExitCode(err))
 	}
 }
 
 func init() {
+	cobra.OnInitialize(initLogging)
 	initFlags()
 	initConfig()
 	initCommands()
@@ -38,16 +67,89 @@ func initConfig() {
 
 	_ = viper.SafeWriteConfig()
 	_ = viper.ReadInConfig()
+
+	if err := utils.MigrateSettings(); err != nil {
+		fmt.Fprintln(os.Stderr, "could not migrate the configuration file:", err)
+	}
+}
+
+func initLogging() {
+	if !utils.Options.Debug {
+		log.SetOutput(io.Discard)
+
+		return
+	}
+
+	// An invalid profile name is reported before the command runs, it can't be used as a directory
+	if utils.ValidateProfileName(utils.ActiveProfile()) != nil {
+		return
+	}
+
+	fileName, err := utils.DebugLogPath(utils.ActiveProfile())
+
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(fileName), 0700)
+	}
+
+	// The TUI owns the terminal, so the debug output is written to a file instead
+	if err == nil {
+		_, err = tea.LogToFile(fileName, "debug")
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "could not open the debug log file:", err)
+	}
+}
+
+// getDebugFlagHelp shows where the debug log of the default profile is, since the flags are defined before the
+// profile is known
+func getDebugFlagHelp() string {
+	fileName, err := utils.DebugLogPath(utils.DefaultProfile)
+
+	if err != nil {
+		fileName = utils.DebugLogFile
+	}
+
+	return fmt.Sprintf("Write debug information (like API retries) to %s, or to profiles/{profile}/%s next to it for other profiles", fileName, utils.DebugLogFile)
+}
+
+func validateOutput() error {
+	switch utils.Options.Output {
+	case "", utils.OutputJSON, utils.OutputCSV, utils.OutputTSV, utils.OutputTable:
+		return nil
+	default:
+		return fmt.Errorf(utils.InvalidOutputError, utils.Options.Output)
+	}
 }
 
 func initCommands() {
 	// Auth commands
 	rootCmd.AddCommand(account.LoginCommand())
 	rootCmd.AddCommand(account.LogoutCommand())
+	rootCmd.AddCommand(profile.ProfileCommand())
 	rootCmd.AddCommand(playlist.ListCommand())
 	rootCmd.AddCommand(playlist.SearchCommand())
+	rootCmd.AddCommand(playlist.DupesCommand())
+	rootCmd.AddCommand(playlist.ExportCommand())
+	rootCmd.AddCommand(playlist.CheckCommand())
+	rootCmd.AddCommand(playlist.DiffCommand())
+	rootCmd.AddCommand(playlist.DedupeCommand())
+	rootCmd.AddCommand(playlist.AddCommand())
+	rootCmd.AddCommand(playlist.MergeCommand())
+	rootCmd.AddCommand(playlist.UndoCommand())
+	rootCmd.AddCommand(playlist.HistoryCommand())
+	rootCmd.AddCommand(cache.CacheCommand())
+	rootCmd.AddCommand(dev.DevCommand())
 }
 
 func initFlags() {
+	flags := rootCmd.PersistentFlags()
+
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	flags.BoolVar(&utils.Options.Debug, "debug", false, getDebugFlagHelp())
+	flags.IntVar(&utils.Options.MaxRetries, "max-retries", utils.DefaultMaxRetries, "Maximum number of retries for throttled or failed API requests")
+	flags.StringVarP(&utils.Options.Output, "output", "o", "", "Print the results as json, csv, tsv or table instead of starting the interactive UI")
+	flags.BoolVar(&utils.Options.Refresh, "refresh", false, "Download the tracks of the playlists again instead of using the cached ones")
+	flags.StringVar(&utils.Options.Profile, "profile", "", "Use the profile instead of the active one")
+	flags.IntVar(&utils.Options.Concurrency, "concurrency", utils.DefaultConcurrency, "Maximum number of concurrent requests when fetching the tracks of a playlist")
 }
@@ -15,7 +15,7 @@ type UserAccount struct {
 
 func GetAccountInformation() (*UserAccount, error) {
 	var user = new(UserAccount)
-	var url = utils.SpotifyAPIBaseURL + "/me"
+	var url = utils.APIBaseURL() + "/me"
 	err := MakeRequest(http.MethodGet, url, nil, user)
 
 	if err != nil {
@@ -28,6 +28,6 @@ func GetAccountInformation() (*UserAccount, error) {
 }
 
 func storeAccountInformation(user *UserAccount) {
-	viper.Set("user_id", user.Id)
+	viper.Set(utils.ProfileKey("user_id"), user.Id)
 	viper.WriteConfig()
 }
@@ -0,0 +1,270 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/CarlosGMI/Playlistify/utils"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/viper"
+)
+
+// searchResultsLimit is the number of tracks requested to Spotify for every free text entry
+const searchResultsLimit = 10
+
+type tracksById struct {
+	Tracks []*trackInfo `json:"tracks"`
+}
+
+type trackSearch struct {
+	Tracks struct {
+		Items []trackInfo `json:"items"`
+	} `json:"tracks"`
+}
+
+// trackToAdd is a track resolved from one of the entries of the add command
+type trackToAdd struct {
+	entry     string
+	track     trackInfo
+	duplicate string
+}
+
+// AddTracksToPlaylist resolves the entries, which can be track URIs, URLs or "Artist - Title" texts searched in
+// Spotify, and shows the tracks that will be added. Tracks that look like duplicates of the tracks of the playlist
+// (or of the previous entries) are skipped unless force is enabled. The tracks are inserted at the (1-based)
+// position, or appended when it's 0
+func AddTracksToPlaylist(playlistId string, entries []string, position int, force bool) tea.Msg {
+	var message = TableResultsMsg{TableType: utils.AddTable}
+	var tracksToAdd []trackToAdd
+	playlist, tracks, err := resolvePlaylistTracks(playlistId)
+
+	if err != nil {
+		return errorToMsg(err)
+	}
+
+	if !isOwnPlaylist(playlist, viper.GetString(utils.ProfileKey("user_id"))) {
+		return PlaylistsErrorMsg{fmt.Sprintf(utils.NotEditablePlaylistError, playlist.Name)}
+	}
+
+	if position < 0 || position > len(tracks)+1 {
+		return PlaylistsErrorMsg{fmt.Sprintf(utils.InvalidPositionError, position, len(tracks)+1)}
+	}
+
+	resolvedTracks, err := resolveTracks(entries)
+
+	if err != nil {
+		return PlaylistsErrorMsg{err.Error()}
+	}
+
+	for _, resolved := range resolvedTracks {
+		resolved.duplicate = findExistingTrack(resolved.track, tracks, tracksToAdd)
+		action := utils.AddAction
+
+		if resolved.duplicate != "" && !force {
+			action = utils.SkipAction
+		} else {
+			tracksToAdd = append(tracksToAdd, resolved)
+		}
+
+		message.appendRow([]string{action, resolved.entry, resolved.track.Name, resolved.track.formattedArtists(), resolved.duplicate})
+	}
+
+	if len(tracksToAdd) == 0 {
+		message.Title = fmt.Sprintf(utils.NothingToAddTitle, playlist.Name)
+
+		return message
+	}
+
+	message.Title = fmt.Sprintf(utils.AddConfirmationTitle, len(tracksToAdd), playlist.Name, len(resolvedTracks)-len(tracksToAdd))
+
+	return ConfirmationMsg{
+		TableResultsMsg: message,
+		ProgressText:    "Adding the tracks...",
+		Action: func() tea.Msg {
+			return addResolvedTracks(playlist, tracksToAdd, position, len(tracks))
+		},
+	}
+}
+
+// addResolvedTracks inserts the tracks and records the addition in the journal, with the positions the tracks got
+func addResolvedTracks(playlist *playlist, tracksToAdd []trackToAdd, position int, totalTracks int) tea.Msg {
+	var message = TableResultsMsg{TableType: utils.AddTable}
+	var uris []string
+	var entry = journalEntry{
+		Operation:      utils.AddOperation,
+		PlaylistId:     playlist.Id,
+		PlaylistName:   playlist.Name,
+		SnapshotBefore: playlist.SnapshotId,
+	}
+
+	if position == 0 {
+		position = totalTracks + 1
+	}
+
+	for _, item := range tracksToAdd {
+		uris = append(uris, item.track.Uri)
+	}
+
+	added, err := addTracks(playlist, uris, position)
+
+	for i, item := range tracksToAdd[:added] {
+		entry.Added = append(entry.Added, journalTrack{item.track.Uri, position + i, item.track.Name, item.track.formattedArtists()})
+		message.appendRow([]string{utils.AddedAction, item.entry, item.track.Name, item.track.formattedArtists(), item.duplicate})
+	}
+
+	if added > 0 {
+		entry.SnapshotAfter = playlist.SnapshotId
+
+		if journalErr := recordOperation(&entry); journalErr != nil {
+			return PlaylistsErrorMsg{fmt.Sprintf(utils.AddJournalError, added, playlist.Name, journalErr.Error())}
+		}
+	}
+
+	if err != nil && added == 0 {
+		return PlaylistsErrorMsg{err.Error()}
+	}
+
+	if err != nil {
+		return PlaylistsErrorMsg{fmt.Sprintf(utils.PartialAddError, err.Error(), added, len(tracksToAdd), entry.Id)}
+	}
+
+	message.Title = fmt.Sprintf(utils.AddTitle, added, playlist.Name, entry.Id)
+
+	return message
+}
+
+// resolveTracks gets the tracks of the URIs and URLs with a single request and searches the rest of the entries
+func resolveTracks(entries []string) ([]trackToAdd, error) {
+	var resolved = make([]trackToAdd, len(entries))
+	var ids []string
+	var idEntries []int
+	var notFound []string
+
+	for i, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		resolved[i].entry = entry
+
+		if id, ok := parseSpotifyId(entry, "track"); ok {
+			ids = append(ids, id)
+			idEntries = append(idEntries, i)
+
+			continue
+		}
+
+		track, err := searchTrack(parseTrackListLine(entry))
+
+		if err != nil {
+			return nil, err
+		}
+
+		if track == nil {
+			notFound = append(notFound, entry)
+
+			continue
+		}
+
+		resolved[i].track = *track
+	}
+
+	for start := 0; start < len(ids); start += utils.TracksByIdLimit {
+		end := start + utils.TracksByIdLimit
+
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		tracks, err := getTracksById(ids[start:end])
+
+		if err != nil {
+			return nil, err
+		}
+
+		for i, track := range tracks {
+			if track == nil {
+				notFound = append(notFound, resolved[idEntries[start+i]].entry)
+			} else {
+				resolved[idEntries[start+i]].track = *track
+			}
+		}
+	}
+
+	if len(notFound) > 0 {
+		return nil, fmt.Errorf(utils.TracksNotFoundError, strings.Join(notFound, `", "`))
+	}
+
+	return resolved, nil
+}
+
+func getTracksById(ids []string) ([]*trackInfo, error) {
+	var results = new(tracksById)
+	var url = fmt.Sprintf("%s/tracks?ids=%s", utils.APIBaseURL(), strings.Join(ids, ","))
+
+	if err := MakeRequest(http.MethodGet, url, nil, results); err != nil {
+		return nil, err
+	}
+
+	if len(results.Tracks) != len(ids) {
+		return nil, errors.New(utils.UnexpectedResponseError)
+	}
+
+	return results.Tracks, nil
+}
+
+// searchTrack looks for the entry in the Spotify catalog and returns the result that best matches its title and
+// artist, using the same scoring of the check command. It returns nil when none of the results match the entry
+func searchTrack(entry trackListEntry) (*trackInfo, error) {
+	var results = new(trackSearch)
+	var bestMatch *trackInfo
+	var bestScore float64
+	var query = "track:" + entry.title
+
+	if entry.artist != "" {
+		query += " artist:" + entry.artist
+	}
+
+	parameters := url.Values{
+		"q":     {query},
+		"type":  {"track"},
+		"limit": {strconv.Itoa(searchResultsLimit)},
+	}
+	url := fmt.Sprintf("%s/search?%s", utils.APIBaseURL(), parameters.Encode())
+
+	if err := MakeRequest(http.MethodGet, url, nil, results); err != nil {
+		return nil, err
+	}
+
+	items := results.Tracks.Items
+
+	for i, item := range items {
+		status, matches := checkEntry(entry, []playlistTrack{{position: i + 1, track: item}})
+
+		if status != utils.NotFoundStatus && matches[0].score > bestScore {
+			bestMatch, bestScore = &items[i], matches[0].score
+		}
+	}
+
+	return bestMatch, nil
+}
+
+// findExistingTrack explains why the track looks like a duplicate of a track of the playlist or of one of the
+// tracks that will be added before it. It uses the criteria of findDuplicates, not the fuzzy matching of matchTerm,
+// so only the tracks that dupes would group are reported
+func findExistingTrack(track trackInfo, tracks []playlistTrack, tracksToAdd []trackToAdd) string {
+	for _, item := range tracks {
+		if reason, isDuplicate := getDuplicateReason(item.track, track, true); isDuplicate {
+			return fmt.Sprintf(utils.ExistingTrackNote, item.position, item.track.Name, reason)
+		}
+	}
+
+	for _, item := range tracksToAdd {
+		if reason, isDuplicate := getDuplicateReason(item.track, track, true); isDuplicate {
+			return fmt.Sprintf(utils.RepeatedEntryNote, item.entry, reason)
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/CarlosGMI/Playlistify/services/fakeserver"
+	"github.com/CarlosGMI/Playlistify/utils"
+)
+
+const (
+	roadTrip2Id      = "5ABHKGoOzxkaa28ttQV9sE"
+	bringMeToLifeUri = "spotify:track:1gXu5tbKNm4e6mhIDy3UeZ"
+	clocksUri        = "spotify:track:zckkEQTJgTMGf3cLBcTRiN"
+	sevenNationUri   = "spotify:track:PVRHsPGKB0E7d32ojcD27h"
+	numbUri          = "spotify:track:kY9pF34Qy6nB3Wwd25rq4f"
+)
+
+func TestResolveTracksWithFakeServer(t *testing.T) {
+	useFakeServer(t, fakeserver.Options{TokenTTL: 3600})
+
+	tests := []struct {
+		name    string
+		entries []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "uris, urls and searches",
+			entries: []string{bringMeToLifeUri, "https://open.spotify.com/track/zckkEQTJgTMGf3cLBcTRiN?si=abc", "The White Stripes - Seven Nation Army"},
+			want:    []string{bringMeToLifeUri, clocksUri, sevenNationUri},
+		},
+		{
+			name:    "unknown uri",
+			entries: []string{"spotify:track:0000000000000000000000"},
+			wantErr: true,
+		},
+		{
+			name:    "search without results",
+			entries: []string{"Nobody - Nothing At All"},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var uris []string
+			resolved, err := resolveTracks(test.entries)
+
+			if (err != nil) != test.wantErr {
+				t.Fatalf("resolveTracks() error = %v, want error %v", err, test.wantErr)
+			}
+
+			for i, item := range resolved {
+				uris = append(uris, item.track.Uri)
+
+				if item.entry != test.entries[i] {
+					t.Errorf("the entry of the track %d is %q, want %q", i, item.entry, test.entries[i])
+				}
+			}
+
+			if !test.wantErr && fmt.Sprint(uris) != fmt.Sprint(test.want) {
+				t.Errorf("resolveTracks() = %v, want %v", uris, test.want)
+			}
+		})
+	}
+}
+
+func TestAddTracksToPlaylistWithFakeServer(t *testing.T) {
+	useFakeServer(t, fakeserver.Options{TokenTTL: 3600})
+
+	entries := []string{bringMeToLifeUri, numbUri, clocksUri, "Coldplay - Clocks"}
+	confirmation, ok := AddTracksToPlaylist(roadTrip2Id, entries, 2, false).(ConfirmationMsg)
+
+	if !ok {
+		t.Fatalf("AddTracksToPlaylist() = %#v, want a confirmation", confirmation)
+	}
+
+	if actions := getRowActions(confirmation.TableResultsMsg); actions != "add skip add skip" {
+		t.Errorf("the actions of AddTracksToPlaylist() are %q, want the duplicates skipped", actions)
+	}
+
+	if msg, ok := confirmation.Action().(TableResultsMsg); !ok || len(msg.Results) != 2 {
+		t.Fatalf("the add action = %#v", msg)
+	}
+
+	_, tracks, err := resolvePlaylistTracks(roadTrip2Id)
+
+	if err != nil {
+		t.Fatalf("resolvePlaylistTracks() error = %v", err)
+	}
+
+	if len(tracks) != 17 || tracks[1].track.Uri != bringMeToLifeUri || tracks[2].track.Uri != clocksUri {
+		t.Errorf("the playlist has %d tracks with %q and %q at positions 2 and 3", len(tracks), tracks[1].track.Uri, tracks[2].track.Uri)
+	}
+
+	journal, err := readJournal()
+
+	if err != nil || len(journal) != 1 || journal[0].Operation != utils.AddOperation || len(journal[0].Added) != 2 {
+		t.Errorf("the journal is %#v, %v, want the added tracks", journal, err)
+	}
+}
+
+func TestAddTracksToPlaylistOptionsWithFakeServer(t *testing.T) {
+	useFakeServer(t, fakeserver.Options{TokenTTL: 3600})
+
+	tests := []struct {
+		name        string
+		position    int
+		force       bool
+		wantActions string
+		wantErr     string
+	}{
+		{"duplicates are skipped", 0, false, "skip", ""},
+		{"duplicates are added with force", 0, true, "add", ""},
+		{"after the last track", 16, false, "skip", ""},
+		{"position after the end", 17, false, "", fmt.Sprintf(utils.InvalidPositionError, 17, 16)},
+		{"negative position", -1, false, "", fmt.Sprintf(utils.InvalidPositionError, -1, 16)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var actions string
+
+			switch msg := AddTracksToPlaylist(roadTrip2Id, []string{numbUri}, test.position, test.force).(type) {
+			case ConfirmationMsg:
+				actions = getRowActions(msg.TableResultsMsg)
+			case TableResultsMsg:
+				actions = getRowActions(msg)
+			case PlaylistsErrorMsg:
+				if msg.Message != test.wantErr {
+					t.Errorf("AddTracksToPlaylist() error = %q, want %q", msg.Message, test.wantErr)
+				}
+
+				return
+			default:
+				t.Fatalf("AddTracksToPlaylist() = %#v", msg)
+			}
+
+			if test.wantErr != "" || actions != test.wantActions {
+				t.Errorf("the actions of AddTracksToPlaylist() are %q, want %q and error %q", actions, test.wantActions, test.wantErr)
+			}
+		})
+	}
+
+	wantErr := fmt.Sprintf(utils.NotEditablePlaylistError, "Followed Hits")
+
+	if msg, ok := AddTracksToPlaylist("0vvXsWCC9xrXsKd4FyS8kM", []string{bringMeToLifeUri}, 0, false).(PlaylistsErrorMsg); !ok || msg.Message != wantErr {
+		t.Errorf("AddTracksToPlaylist() to a playlist of another user = %#v, want %q", msg, wantErr)
+	}
+}
+
+// getRowActions joins the first column of the rows, which is the action taken for every track
+func getRowActions(msg TableResultsMsg) string {
+	var actions []string
+
+	for _, row := range msg.Results {
+		actions = append(actions, row[0])
+	}
+
+	return strings.Join(actions, " ")
+}
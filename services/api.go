@@ -1,14 +1,33 @@
 package services
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
+	"strconv"
+	"time"
 
-	"github.com/spf13/viper"
+	"github.com/CarlosGMI/Playlistify/utils"
 )
 
+const retryBaseDelay = 500 * time.Millisecond
+const retryMaxDelay = 30 * time.Second
+
+// httpClient is shared by all the requests so the connections to Spotify are kept alive and reused
+var httpClient = &http.Client{
+	Timeout: 30 * time.Second,
+	Transport: &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 20,
+		IdleConnTimeout:     90 * time.Second,
+	},
+}
+
 type spotifyErrorData struct {
 	Message string `json:"message"`
 	Status  int    `json:"status"`
@@ -18,39 +37,161 @@ type spotifyError struct {
 	Error spotifyErrorData `json:"error"`
 }
 
+// requestError is returned when Spotify answers a request with an error status
+type requestError struct {
+	Status  int
+	Message string
+}
+
+func (err *requestError) Error() string {
+	return fmt.Sprintf("%s (%v)", err.Message, err.Status)
+}
+
+// MakeRequest sends an authenticated request to the Spotify API and decodes its response into resultFormat. The
+// resultFormat can be nil for the requests whose response isn't needed
 func MakeRequest(method string, url string, body io.Reader, resultFormat interface{}) error {
 	var errorResults = new(spotifyError)
-	client := &http.Client{}
-	request, error := http.NewRequest(method, url, body)
-	request.Close = true
+	var payload []byte
 
-	if error != nil {
-		return error
+	if body != nil {
+		content, err := io.ReadAll(body)
+
+		if err != nil {
+			return err
+		}
+
+		payload = content
 	}
 
-	request.Header.Add("Authorization", "Bearer "+viper.GetString("token"))
-	request.Header.Add("Content-Type", "application/json")
-	request.Header.Add("Accept-Encoding", "identity")
+	accessToken, err := getValidToken()
+
+	if err != nil {
+		return err
+	}
 
-	response, err := client.Do(request)
+	response, err := sendRequestWithRetries(method, url, payload, accessToken)
 
 	if err != nil {
 		return err
 	}
 
+	// The token might have been revoked or expired earlier than expected, so it's refreshed and the request retried once
+	if response.StatusCode == http.StatusUnauthorized {
+		response.Body.Close()
+
+		if accessToken, err = forceTokenRefresh(accessToken); err != nil {
+			return err
+		}
+
+		if response, err = sendRequestWithRetries(method, url, payload, accessToken); err != nil {
+			return err
+		}
+	}
+
 	defer response.Body.Close()
 
 	if response.StatusCode >= 200 && response.StatusCode < 300 {
-		if err := json.NewDecoder(response.Body).Decode(resultFormat); err != nil {
+		content, err := io.ReadAll(response.Body)
+
+		// Some of the endpoints that modify playlists answer without a body
+		if err != nil || resultFormat == nil || len(bytes.TrimSpace(content)) == 0 {
 			return err
 		}
 
-		return nil
+		return json.Unmarshal(content, resultFormat)
 	}
 
 	if err := json.NewDecoder(response.Body).Decode(errorResults); err != nil {
-		return err
+		return &requestError{response.StatusCode, http.StatusText(response.StatusCode)}
+	}
+
+	return &requestError{errorResults.Error.Status, errorResults.Error.Message}
+}
+
+// isForbiddenError tells if Spotify rejected the request because the user can't access the resource, usually
+// because the token was granted without the scopes that modify playlists
+func isForbiddenError(err error) bool {
+	var apiError *requestError
+
+	return errors.As(err, &apiError) && apiError.Status == http.StatusForbidden
+}
+
+// sendRequestWithRetries retries the request when Spotify is throttling it (429) or failing (5xx), waiting for
+// the time defined by the Retry-After header or an exponential backoff with jitter. POST requests are only retried
+// when they're throttled, since a failed one might have been applied anyway
+func sendRequestWithRetries(method string, url string, payload []byte, accessToken string) (*http.Response, error) {
+	maxRetries := utils.Options.MaxRetries
+
+	for attempt := 1; ; attempt++ {
+		response, err := sendRequest(method, url, payload, accessToken)
+
+		if err != nil || !isRetryableStatus(method, response.StatusCode) || attempt > maxRetries {
+			return response, err
+		}
+
+		delay := getRetryDelay(response, attempt)
+		response.Body.Close()
+
+		utils.Debugf("%s %s answered %d, retry %d/%d in %s", method, url, response.StatusCode, attempt, maxRetries, delay)
+		time.Sleep(delay)
+	}
+}
+
+// isRetryableStatus doesn't retry the 5xx errors of POST requests, which would add the tracks or create the playlist
+// twice if Spotify applied the change before failing
+func isRetryableStatus(method string, status int) bool {
+	if status == http.StatusTooManyRequests {
+		return true
+	}
+
+	return status >= http.StatusInternalServerError && method != http.MethodPost
+}
+
+func getRetryDelay(response *http.Response, attempt int) time.Duration {
+	jitter := time.Duration(rand.Int63n(int64(retryBaseDelay)))
+
+	if retryAfter := response.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			return clampRetryDelay(time.Duration(seconds)*time.Second + jitter)
+		}
+
+		if date, err := http.ParseTime(retryAfter); err == nil {
+			return clampRetryDelay(time.Until(date) + jitter)
+		}
+	}
+
+	delay := retryBaseDelay << (attempt - 1)
+
+	if delay > retryMaxDelay || delay <= 0 {
+		delay = retryMaxDelay
 	}
 
-	return fmt.Errorf("%s (%v)", errorResults.Error.Message, errorResults.Error.Status)
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)))
+}
+
+// clampRetryDelay keeps a Retry-After header from blocking the CLI for longer than the maximum backoff
+func clampRetryDelay(delay time.Duration) time.Duration {
+	if delay > retryMaxDelay {
+		return retryMaxDelay
+	}
+
+	if delay < 0 {
+		return 0
+	}
+
+	return delay
+}
+
+func sendRequest(method string, url string, payload []byte, accessToken string) (*http.Response, error) {
+	request, err := http.NewRequest(method, url, bytes.NewReader(payload))
+
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Add("Authorization", "Bearer "+accessToken)
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("Accept-Encoding", "identity")
+
+	return httpClient.Do(request)
 }
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/CarlosGMI/Playlistify/services/fakeserver"
+	"github.com/CarlosGMI/Playlistify/utils"
+	"github.com/spf13/viper"
+)
+
+// statusRecorder counts the statuses answered by the fake server
+type statusRecorder struct {
+	mutex    sync.Mutex
+	statuses map[int]int
+}
+
+type recordedWriter struct {
+	http.ResponseWriter
+	recorder *statusRecorder
+}
+
+func (writer recordedWriter) WriteHeader(status int) {
+	writer.recorder.mutex.Lock()
+	writer.recorder.statuses[status]++
+	writer.recorder.mutex.Unlock()
+	writer.ResponseWriter.WriteHeader(status)
+}
+
+func (recorder *statusRecorder) count(status int) int {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	return recorder.statuses[status]
+}
+
+func TestListAndSearchWithFakeServer(t *testing.T) {
+	recorder := useFakeServer(t, fakeserver.Options{RateLimitEvery: 3, TokenTTL: 3600})
+
+	if msg := GetPlaylists(); msg != PlaylistsMsg("") {
+		t.Fatalf("GetPlaylists() = %#v", msg)
+	}
+
+	rows, _, err := PrintPlaylists()
+
+	if err != nil {
+		t.Fatalf("PrintPlaylists() error = %v", err)
+	}
+
+	// The playlists of other users are only listed when they're collaborative
+	var names []string
+
+	for _, row := range rows {
+		names = append(names, row[1])
+	}
+
+	if strings.Join(names, ", ") != "Road Trip, Road Trip 2, Chill Evenings" {
+		t.Errorf("PrintPlaylists() = %v", names)
+	}
+
+	search, ok := SearchInPlaylist("Road Trip", "numb").(SearchResultsMsg)
+
+	if !ok || search.Status != utils.SearchComplete || len(search.Results) == 0 {
+		t.Fatalf("SearchInPlaylist() = %#v", search)
+	}
+
+	if search.Results[0][0] != "1" || search.Results[0][1] != "Numb" {
+		t.Errorf("the first result of SearchInPlaylist() is %v, want Numb at position 1", search.Results[0])
+	}
+
+	allSearch, ok := SearchInAllPlaylists("numb").(SearchResultsMsg)
+
+	if !ok || allSearch.Status != utils.SearchComplete || len(allSearch.Results) < len(search.Results) {
+		t.Fatalf("SearchInAllPlaylists() = %#v", allSearch)
+	}
+
+	if recorder.count(http.StatusTooManyRequests) == 0 {
+		t.Error("the fake server never throttled the requests, so the retries weren't tested")
+	}
+}
+
+func TestRateLimitWithoutRetries(t *testing.T) {
+	recorder := useFakeServer(t, fakeserver.Options{RateLimitEvery: 1, TokenTTL: 3600})
+	maxRetries := utils.Options.MaxRetries
+	utils.Options.MaxRetries = 0
+
+	t.Cleanup(func() {
+		utils.Options.MaxRetries = maxRetries
+	})
+
+	msg, ok := GetPlaylists().(PlaylistsErrorMsg)
+
+	if !ok || !strings.Contains(msg.Message, "429") {
+		t.Errorf("GetPlaylists() = %#v, want a rate limit error", msg)
+	}
+
+	if count := recorder.count(http.StatusTooManyRequests); count != 1 {
+		t.Errorf("the request was sent %d times, want it sent once", count)
+	}
+}
+
+func TestIsRetryableStatus(t *testing.T) {
+	tests := []struct {
+		method string
+		status int
+		want   bool
+	}{
+		{http.MethodGet, http.StatusTooManyRequests, true},
+		{http.MethodGet, http.StatusBadGateway, true},
+		{http.MethodDelete, http.StatusInternalServerError, true},
+		{http.MethodPost, http.StatusTooManyRequests, true},
+		{http.MethodPost, http.StatusInternalServerError, false},
+		{http.MethodGet, http.StatusNotFound, false},
+		{http.MethodGet, http.StatusOK, false},
+	}
+
+	for _, test := range tests {
+		if got := isRetryableStatus(test.method, test.status); got != test.want {
+			t.Errorf("isRetryableStatus(%s, %d) = %v, want %v", test.method, test.status, got, test.want)
+		}
+	}
+}
+
+func TestGetRetryDelay(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter string
+		min        time.Duration
+		max        time.Duration
+	}{
+		{"seconds", "2", 2 * time.Second, 2*time.Second + retryBaseDelay},
+		{"too many seconds", "3600", retryMaxDelay, retryMaxDelay},
+		{"date in the past", "Mon, 02 Jan 2006 15:04:05 GMT", 0, retryBaseDelay},
+		{"date too far", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat), retryMaxDelay, retryMaxDelay},
+		{"invalid header", "soon", retryBaseDelay / 2, retryBaseDelay},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := &http.Response{Header: http.Header{"Retry-After": {test.retryAfter}}}
+
+			if delay := getRetryDelay(response, 1); delay < test.min || delay > test.max {
+				t.Errorf("getRetryDelay() = %v, want between %v and %v", delay, test.min, test.max)
+			}
+		})
+	}
+}
+
+// useFakeServer serves the fake Spotify API and logs in like the login command does. The playlists and the tracks
+// are stored in temporary directories
+func useFakeServer(t *testing.T, options fakeserver.Options) *statusRecorder {
+	var recorder = &statusRecorder{statuses: map[int]int{}}
+	var client = &http.Client{
+		CheckRedirect: func(request *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	handler, err := fakeserver.NewHandler(options)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serverURL := useTestAPI(t, http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		handler.ServeHTTP(recordedWriter{writer, recorder}, request)
+	}))
+
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	viper.Set(utils.ProfileKey("user_id"), "fakeuser")
+
+	t.Cleanup(func() {
+		viper.Set(utils.ProfileKey("user_id"), nil)
+		viper.Set(utils.ProfileKey("playlists"), nil)
+	})
+
+	response, err := client.Get(serverURL + "/authorize?" + url.Values{"redirect_uri": {utils.CallbackURL()}, "state": {"state"}}.Encode())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response.Body.Close()
+	callbackURL, err := url.Parse(response.Header.Get("Location"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := requestSpotifyToken(callbackURL.Query().Get("code"), "")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	storeTokenInformation(token)
+
+	return recorder
+}
+
+// useTestAPI sends the requests of the test to the handler, using an access token that doesn't need to be refreshed
+func useTestAPI(t *testing.T, handler http.Handler) string {
+	server := httptest.NewServer(handler)
+
+	t.Setenv("PLAYLISTIFY_API_BASE_URL", server.URL+"/v1")
+	t.Setenv("PLAYLISTIFY_ACCOUNTS_BASE_URL", server.URL)
+	useTestCredentials(t, credentials{AccessToken: "token", Expiration: time.Now().Add(time.Hour).Unix()})
+
+	t.Cleanup(server.Close)
+
+	return server.URL
+}
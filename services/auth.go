@@ -2,19 +2,24 @@ package services
 
 import (
 	"context"
+	_ "embed"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"html/template"
+	"log"
 	"math/rand"
+	"net"
 	"net/http"
 	"net/url"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/CarlosGMI/Playlistify/utils"
 	tea "github.com/charmbracelet/bubbletea"
 	pkce "github.com/nirasan/go-oauth-pkce-code-verifier"
 	"github.com/pkg/browser"
-	"github.com/spf13/viper"
 )
 
 type authorizationValues struct {
@@ -28,6 +33,10 @@ type token struct {
 	ExpiresIn    int    `json:"expires_in"`
 	RefreshToken string `json:"refresh_token"`
 }
+type tokenError struct {
+	Error       string `json:"error"`
+	Description string `json:"error_description"`
+}
 type AuthMsg struct {
 	ErrorType int
 	Message   string
@@ -38,36 +47,97 @@ type AuthorizedMsg string
 type LoggedInMsg string
 type LoggedInUserMsg struct{ Message string }
 
+// AuthorizationURLMsg is the URL the user has to open to authorize the app when the browser isn't opened
+type AuthorizationURLMsg string
+
+// callbackPage is the content of the page the browser shows after the authorization
+type callbackPage struct {
+	Success bool
+	Title   string
+	Message string
+}
+
+//go:embed templates/callback.html
+var callbackTemplateContent string
+var callbackTemplate = template.Must(template.New("callback").Parse(callbackTemplateContent))
+
 var pkceVerifier, pkceChallenge string
 var authorization authorizationValues
 
+// manualState is the state sent to Spotify in the authorization that is completed by pasting the callback URL
+var manualState string
+
+// authorizationCodePattern matches an authorization code pasted on its own, without the rest of the callback URL
+var authorizationCodePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
 func InitAuthentication() tea.Msg {
-	token := viper.GetString("token")
-	expiration := viper.GetInt64("token_expiration")
+	current, err := getCredentials()
 
-	if token == "" {
+	if err != nil {
+		return AuthErrorMsg{utils.CredentialsErrorCode, err.Error()}
+	}
+
+	if current.AccessToken == "" {
 		return NotAuthenticatedMsg{utils.NotLoggedInCode, utils.NotLoggedInError}
 	}
 
-	if time.Now().Unix() > expiration {
+	if time.Now().Unix() > current.Expiration {
 		return NotAuthenticatedMsg{utils.ExpiredTokenCode, utils.ExpiredTokenError}
 	}
 
 	return AuthErrorMsg{utils.AlreadyLoggedInCode, utils.AlreadyLoggedInError}
 }
 
-func Authenticate() tea.Msg {
+// CheckAuthentication verifies that there's a logged in user for the commands that don't run the TUI. An expired
+// token is refreshed by MakeRequest, so it isn't considered an error
+func CheckAuthentication() error {
+	switch msg := InitAuthentication().(type) {
+	case NotAuthenticatedMsg:
+		if msg.ErrorType == utils.NotLoggedInCode {
+			return errors.New(msg.Message)
+		}
+	case AuthErrorMsg:
+		if msg.ErrorType == utils.CredentialsErrorCode {
+			return errors.New(msg.Message)
+		}
+	}
+
+	return nil
+}
+
+// Authenticate opens the authorization URL in the browser and waits for the callback. Cancelling the context stops
+// the wait
+func Authenticate(ctx context.Context) tea.Msg {
+	timeout, err := utils.LoginTimeout()
+
+	if err != nil {
+		return AuthErrorMsg{
+			Message: err.Error(),
+		}
+	}
+
 	initPKCECodeChallenge()
 	state := generateRandomState()
 	uri := buildAuthURI(pkceChallenge, state)
 
+	// The listener is opened first, so a busy port fails before the user authorizes the app in the browser
+	listener, err := net.Listen("tcp", utils.CallbackAddress())
+
+	if err != nil {
+		return AuthErrorMsg{
+			Message: fmt.Sprintf(utils.CallbackListenError, utils.CallbackAddress(), err.Error()),
+		}
+	}
+
 	if err := browser.OpenURL(uri); err != nil {
+		listener.Close()
+
 		return AuthErrorMsg{
 			Message: err.Error(),
 		}
 	}
 
-	authorization = listenForSpotifyAuthorization(state)
+	authorization = listenForSpotifyAuthorization(ctx, listener, state, timeout)
 
 	if authorization.err != nil {
 		return AuthErrorMsg{
@@ -78,6 +148,59 @@ func Authenticate() tea.Msg {
 	return AuthorizedMsg("Authorized")
 }
 
+// StartManualAuthorization builds the authorization URL for the --no-browser login, which prints it instead of
+// opening the browser and listening for the callback
+func StartManualAuthorization() tea.Msg {
+	initPKCECodeChallenge()
+	manualState = generateRandomState()
+
+	return AuthorizationURLMsg(buildAuthURI(pkceChallenge, manualState))
+}
+
+// CompleteManualAuthorization takes the callback URL the browser was redirected to, or only its code, pasted by the
+// user. The state of a URL has to be the one of the authorization URL
+func CompleteManualAuthorization(input string) tea.Msg {
+	code, err := parseAuthorizationInput(strings.TrimSpace(input), manualState)
+
+	if err != nil {
+		return AuthErrorMsg{
+			Message: err.Error(),
+		}
+	}
+
+	authorization = authorizationValues{code: code}
+
+	return AuthorizedMsg("Authorized")
+}
+
+func parseAuthorizationInput(input string, state string) (string, error) {
+	if authorizationCodePattern.MatchString(input) {
+		return input, nil
+	}
+
+	callbackURL, err := url.Parse(input)
+
+	if err != nil || !strings.Contains(input, "?") {
+		return "", errors.New(utils.InvalidCallbackError)
+	}
+
+	queryParams := callbackURL.Query()
+
+	if queryParams.Has("error") {
+		return "", fmt.Errorf(utils.AuthorizationDeniedError, queryParams.Get("error"))
+	}
+
+	if queryParams.Get("state") != state {
+		return "", errors.New(utils.StateMismatchError)
+	}
+
+	if queryParams.Get("code") == "" {
+		return "", errors.New(utils.InvalidCallbackError)
+	}
+
+	return queryParams.Get("code"), nil
+}
+
 func Login() tea.Msg {
 	token, err := requestSpotifyToken(authorization.code, pkceVerifier)
 
@@ -87,7 +210,11 @@ func Login() tea.Msg {
 		}
 	}
 
-	storeTokenInformation(token)
+	if err := storeTokenInformation(token); err != nil {
+		return AuthErrorMsg{
+			Message: err.Error(),
+		}
+	}
 
 	return LoggedInMsg("Authenticated")
 }
@@ -125,9 +252,9 @@ func generateRandomState() string {
 
 func buildAuthURI(pkceChallenge string, state string) string {
 	queryParams := url.Values{
-		"client_id":             {utils.ClientId},
+		"client_id":             {utils.ClientId()},
 		"response_type":         {"code"},
-		"redirect_uri":          {utils.AuthorizationCallbackURL},
+		"redirect_uri":          {utils.CallbackURL()},
 		"state":                 {state},
 		"scope":                 {utils.PlaylistifyScopes},
 		"code_challenge_method": {"S256"},
@@ -135,71 +262,101 @@ func buildAuthURI(pkceChallenge string, state string) string {
 		"show_dialog":           {"false"},
 	}
 
-	return utils.SpotifyAccountBaseURL + "/authorize?" + queryParams.Encode()
+	return utils.AccountsBaseURL() + "/authorize?" + queryParams.Encode()
 }
 
-func listenForSpotifyAuthorization(state string) authorizationValues {
-	var values = authorizationValues{
-		code: "",
-		err:  nil,
-	}
-	server := &http.Server{Addr: utils.AuthorizationPort}
+// listenForSpotifyAuthorization waits for the browser to be redirected to the callback. It gives up when the
+// timeout expires or the context is cancelled, like when the user cancels the login in the TUI
+func listenForSpotifyAuthorization(ctx context.Context, listener net.Listener, state string, timeout time.Duration) authorizationValues {
+	var results = make(chan authorizationValues, 1)
+	// A dedicated mux, since registering the callback twice in http.DefaultServeMux panics
+	var mux = http.NewServeMux()
+	var server = &http.Server{Handler: mux}
 
-	http.HandleFunc("/callback", func(writer http.ResponseWriter, req *http.Request) {
-		code, err := getSpotifyAuthorization(writer, req, state)
-		values.code = code
-		values.err = err
+	mux.HandleFunc(utils.CallbackPath(), func(writer http.ResponseWriter, req *http.Request) {
+		values := getSpotifyAuthorization(writer, req, state)
 
-		go func() {
-			server.Shutdown(context.Background())
-		}()
+		select {
+		case results <- values:
+		default:
+		}
 	})
 
-	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+	go server.Serve(listener)
+
+	defer func() {
+		// Shutdown waits for the page to be sent, but not for a browser that keeps the connection open
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		server.Shutdown(shutdownCtx)
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	select {
+	case values := <-results:
 		return values
-	}
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return authorizationValues{err: fmt.Errorf(utils.LoginTimeoutError, timeout)}
+		}
 
-	return values
+		return authorizationValues{err: errors.New(utils.LoginCancelledError)}
+	}
 }
 
-func getSpotifyAuthorization(writer http.ResponseWriter, req *http.Request, state string) (string, error) {
-	url, _ := url.Parse(req.URL.String())
-	queryParams := url.Query()
+// getSpotifyAuthorization reads the code of the callback and renders the page the browser shows
+func getSpotifyAuthorization(writer http.ResponseWriter, req *http.Request, state string) authorizationValues {
+	var values authorizationValues
+	queryParams := req.URL.Query()
 	code := queryParams.Get("code")
-	spotifyState := queryParams.Get("state")
 
-	if queryParams.Has("error") || spotifyState != state {
-		fmt.Fprintf(writer, "An error occured: "+queryParams.Get("error"))
+	switch {
+	case queryParams.Has("error"):
+		values.err = fmt.Errorf(utils.AuthorizationDeniedError, queryParams.Get("error"))
+	case queryParams.Get("state") != state:
+		// The state is checked before the code, since the code of another login must never be used
+		values.err = errors.New(utils.CallbackStateMismatchError)
+	case code == "":
+		values.err = errors.New(utils.NotAuthorizedError)
+	default:
+		values.code = code
+	}
 
-		return "", errors.New(utils.NotAuthorizedError)
-	} else {
-		fmt.Fprintf(writer, "Authorization successful!")
+	page := callbackPage{
+		Success: values.err == nil,
+		Title:   "Playlistify is authorized",
+		Message: "The login is complete.",
+	}
+
+	writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if values.err != nil {
+		page.Title = "The authorization failed"
+		message := values.err.Error()
+		page.Message = strings.ToUpper(message[:1]) + message[1:] + "."
+		writer.WriteHeader(http.StatusBadRequest)
+	}
 
-		return code, nil
+	if err := callbackTemplate.Execute(writer, page); err != nil {
+		log.Println("could not render the callback page:", err)
 	}
+
+	return values
 }
 
 func requestSpotifyToken(code string, pkceVerifier string) (*token, error) {
 	data := url.Values{
 		"grant_type":    {"authorization_code"},
 		"code":          {code},
-		"redirect_uri":  {utils.AuthorizationCallbackURL},
-		"client_id":     {utils.ClientId},
+		"redirect_uri":  {utils.CallbackURL()},
+		"client_id":     {utils.ClientId()},
 		"code_verifier": {pkceVerifier},
 	}
 
-	response, err := http.PostForm(utils.SpotifyAccountBaseURL+"/api/token", data)
-
-	if err != nil {
-		return nil, err
-	}
-
-	defer response.Body.Close()
-
-	token := new(token)
-	err = json.NewDecoder(response.Body).Decode(token)
-
-	return token, err
+	return requestToken(data)
 }
 
 func RefreshAuthorization() tea.Msg {
@@ -211,19 +368,33 @@ func RefreshAuthorization() tea.Msg {
 		}
 	}
 
-	storeTokenInformation(token)
+	if err := storeTokenInformation(token); err != nil {
+		return AuthErrorMsg{
+			Message: err.Error(),
+		}
+	}
 
 	return LoggedInMsg("Authenticated")
 }
 
 func requestSpotifyRefreshToken() (*token, error) {
+	current, err := getCredentials()
+
+	if err != nil {
+		return nil, err
+	}
+
 	data := url.Values{
 		"grant_type":    {"refresh_token"},
-		"refresh_token": {viper.GetString("refresh_token")},
-		"client_id":     {utils.ClientId},
+		"refresh_token": {current.RefreshToken},
+		"client_id":     {utils.ClientId()},
 	}
 
-	response, err := http.PostForm(utils.SpotifyAccountBaseURL+"/api/token", data)
+	return requestToken(data)
+}
+
+func requestToken(data url.Values) (*token, error) {
+	response, err := http.PostForm(utils.AccountsBaseURL()+"/api/token", data)
 
 	if err != nil {
 		return nil, err
@@ -231,24 +402,40 @@ func requestSpotifyRefreshToken() (*token, error) {
 
 	defer response.Body.Close()
 
+	if response.StatusCode != http.StatusOK {
+		var errorResults = new(tokenError)
+
+		if err := json.NewDecoder(response.Body).Decode(errorResults); err != nil {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf(utils.TokenRequestError, errorResults.Description, errorResults.Error)
+	}
+
 	token := new(token)
 	err = json.NewDecoder(response.Body).Decode(token)
 
 	return token, err
 }
 
-func storeTokenInformation(token *token) {
-	viper.Set("token", token.AccessToken)
-	viper.Set("token_expiration", time.Now().Unix()+int64(token.ExpiresIn))
-	viper.Set("refresh_token", token.RefreshToken)
+func storeTokenInformation(token *token) error {
+	current, err := getCredentials()
 
-	viper.WriteConfig()
-}
+	if err != nil {
+		return err
+	}
+
+	current.AccessToken = token.AccessToken
+	current.Expiration = time.Now().Unix() + int64(token.ExpiresIn)
 
-func EmptyTokenInformation() {
-	viper.Set("token", "")
-	viper.Set("token_expiration", "")
-	viper.Set("refresh_token", "")
+	// Spotify may or may not rotate the refresh token, keep the previous one otherwise
+	if token.RefreshToken != "" {
+		current.RefreshToken = token.RefreshToken
+	}
+
+	return setCredentials(current)
+}
 
-	viper.WriteConfig()
+func EmptyTokenInformation() error {
+	return clearCredentials()
 }
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/CarlosGMI/Playlistify/utils"
+)
+
+func TestParseAuthorizationInput(t *testing.T) {
+	const state = "state123"
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{"code", "AQBx-_9z", "AQBx-_9z", ""},
+		{"callback url", "http://localhost:8080/callback?code=AQBx&state=" + state, "AQBx", ""},
+		{"parameters in another order", "http://127.0.0.1:9000/cb?state=" + state + "&code=AQBx", "AQBx", ""},
+		{"denied authorization", "http://localhost:8080/callback?error=access_denied&state=" + state, "", fmt.Sprintf(utils.AuthorizationDeniedError, "access_denied")},
+		{"another state", "http://localhost:8080/callback?code=AQBx&state=other", "", utils.StateMismatchError},
+		{"missing state", "http://localhost:8080/callback?code=AQBx", "", utils.StateMismatchError},
+		{"missing code", "http://localhost:8080/callback?state=" + state, "", utils.InvalidCallbackError},
+		{"url without query", "http://localhost:8080/callback", "", utils.InvalidCallbackError},
+		{"code with spaces", "AQBx 9z", "", utils.InvalidCallbackError},
+		{"empty input", "", "", utils.InvalidCallbackError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseAuthorizationInput(test.input, state)
+
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Errorf("parseAuthorizationInput(%q) error = %v, want %q", test.input, err, test.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil || got != test.want {
+				t.Errorf("parseAuthorizationInput(%q) = %q, %v, want %q", test.input, got, err, test.want)
+			}
+		})
+	}
+}
+
+func TestListenForSpotifyAuthorization(t *testing.T) {
+	const state = "state123"
+
+	tests := []struct {
+		name       string
+		query      string
+		cancel     bool
+		wantStatus int
+		wantCode   string
+		wantErr    string
+	}{
+		{"authorized", "?code=AQBx&state=" + state, false, http.StatusOK, "AQBx", ""},
+		{"another state", "?code=AQBx&state=other", false, http.StatusBadRequest, "", utils.CallbackStateMismatchError},
+		{"denied authorization", "?error=access_denied&state=" + state, false, http.StatusBadRequest, "", fmt.Sprintf(utils.AuthorizationDeniedError, "access_denied")},
+		{"missing code", "?state=" + state, false, http.StatusBadRequest, "", utils.NotAuthorizedError},
+		{"timeout", "", false, 0, "", fmt.Sprintf(utils.LoginTimeoutError, 100*time.Millisecond)},
+		{"cancelled login", "", true, 0, "", utils.LoginCancelledError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var results = make(chan authorizationValues, 1)
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			go func() {
+				results <- listenForSpotifyAuthorization(ctx, listener, state, 100*time.Millisecond)
+			}()
+
+			if test.cancel {
+				cancel()
+			}
+
+			if test.query != "" {
+				response, err := http.Get("http://" + listener.Addr().String() + utils.CallbackPath() + test.query)
+
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				response.Body.Close()
+
+				if response.StatusCode != test.wantStatus {
+					t.Errorf("the callback status is %d, want %d", response.StatusCode, test.wantStatus)
+				}
+			}
+
+			values := <-results
+
+			if values.code != test.wantCode || (values.err == nil) != (test.wantErr == "") || (values.err != nil && values.err.Error() != test.wantErr) {
+				t.Errorf("listenForSpotifyAuthorization() = %q, %v, want %q, %q", values.code, values.err, test.wantCode, test.wantErr)
+			}
+
+			// The server is shut down, so a late callback can't be answered
+			if response, err := http.Get("http://" + listener.Addr().String() + utils.CallbackPath()); err == nil {
+				response.Body.Close()
+				t.Error("the callback server is still listening after the login")
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CarlosGMI/Playlistify/utils"
+)
+
+// cachedTracks is stored in a file per playlist. The tracks are only used while the snapshot of the playlist and
+// the requested track fields are the same as when they were stored
+type cachedTracks struct {
+	PlaylistId   string    `json:"playlist_id"`
+	PlaylistName string    `json:"playlist_name"`
+	SnapshotId   string    `json:"snapshot_id"`
+	Fields       string    `json:"fields"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	Tracks       []track   `json:"tracks"`
+}
+
+type playlistSnapshot struct {
+	SnapshotId string             `json:"snapshot_id"`
+	Tracks     playlistTracksInfo `json:"tracks"`
+}
+
+// getCachedPages returns the tracks of the playlist split in pages when they are cached and still up to date
+func getCachedPages(playlist *playlist) ([]tracksPage, bool) {
+	var cache = new(cachedTracks)
+	var pages []tracksPage
+
+	if utils.Options.Refresh || playlist.SnapshotId == "" {
+		return nil, false
+	}
+
+	if err := readCacheFile(playlist.Id, cache); err != nil {
+		return nil, false
+	}
+
+	if cache.SnapshotId != playlist.SnapshotId || cache.Fields != utils.TrackFields {
+		return nil, false
+	}
+
+	for start := 0; start < len(cache.Tracks); start += utils.TracksLimit {
+		end := start + utils.TracksLimit
+
+		if end > len(cache.Tracks) {
+			end = len(cache.Tracks)
+		}
+
+		pages = append(pages, tracksPage{len(pages), cache.Tracks[start:end], nil})
+	}
+
+	utils.Debugf("using %d cached tracks of %s (snapshot %s)", len(cache.Tracks), playlist.Id, playlist.SnapshotId)
+
+	return pages, true
+}
+
+// storeCachedPages writes the tracks of the playlist to the cache, unless some of the pages couldn't be fetched
+func storeCachedPages(playlist *playlist, pages []tracksPage) {
+	var cache = cachedTracks{
+		PlaylistId:   playlist.Id,
+		PlaylistName: playlist.Name,
+		SnapshotId:   playlist.SnapshotId,
+		Fields:       utils.TrackFields,
+		UpdatedAt:    time.Now(),
+	}
+
+	if playlist.SnapshotId == "" {
+		return
+	}
+
+	for _, page := range pages {
+		if page.err != nil {
+			return
+		}
+
+		cache.Tracks = append(cache.Tracks, page.tracks...)
+	}
+
+	if err := writeCacheFile(&cache); err != nil {
+		utils.Debugf("could not cache the tracks of %s: %s", playlist.Id, err.Error())
+	}
+}
+
+// refreshPlaylistSnapshot gets the current snapshot and number of tracks of the playlist, since the ones stored
+// by the list command may be outdated
+func refreshPlaylistSnapshot(playlist *playlist) {
+	var current = new(playlistSnapshot)
+	var query = url.Values{
+		"fields": {"snapshot_id,tracks(total)"},
+	}
+	var url = fmt.Sprintf("%s/playlists/%s?%s", utils.APIBaseURL(), playlist.Id, query.Encode())
+
+	if playlist.snapshotChecked {
+		return
+	}
+
+	if err := MakeRequest(http.MethodGet, url, nil, current); err != nil {
+		utils.Debugf("could not get the snapshot of %s: %s", playlist.Id, err.Error())
+		playlist.SnapshotId = ""
+
+		return
+	}
+
+	playlist.SnapshotId = current.SnapshotId
+	playlist.Tracks.Total = current.Tracks.Total
+	playlist.snapshotChecked = true
+}
+
+func ClearTracksCache() error {
+	directory, err := getCacheDirectory()
+
+	if err != nil {
+		return err
+	}
+
+	return os.RemoveAll(directory)
+}
+
+// GetTracksCacheStats lists the cached playlists
+func GetTracksCacheStats() (TableResultsMsg, error) {
+	var message = TableResultsMsg{TableType: utils.CacheTable}
+	var totalTracks, totalSize int64
+	directory, err := getCacheDirectory()
+
+	if err != nil {
+		return message, err
+	}
+
+	files, err := os.ReadDir(directory)
+
+	if err != nil && !os.IsNotExist(err) {
+		return message, err
+	}
+
+	for _, file := range files {
+		var cache = new(cachedTracks)
+		info, err := file.Info()
+
+		if err != nil || readCacheFile(strings.TrimSuffix(file.Name(), ".json"), cache) != nil {
+			continue
+		}
+
+		totalTracks += int64(len(cache.Tracks))
+		totalSize += info.Size()
+		message.appendRow([]string{
+			cache.PlaylistId,
+			cache.PlaylistName,
+			strconv.Itoa(len(cache.Tracks)),
+			formatSize(info.Size()),
+			cache.UpdatedAt.Format(time.RFC3339),
+		})
+	}
+
+	message.Title = fmt.Sprintf(utils.CacheStatsTitle, len(message.Results), totalTracks, formatSize(totalSize), directory)
+
+	return message, nil
+}
+
+func getCacheDirectory() (string, error) {
+	directory, err := os.UserCacheDir()
+
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(utils.ProfileDirectory(filepath.Join(directory, "playlistify"), utils.ActiveProfile()), "tracks"), nil
+}
+
+func readCacheFile(playlistId string, cache *cachedTracks) error {
+	directory, err := getCacheDirectory()
+
+	if err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(filepath.Join(directory, playlistId+".json"))
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(content, cache)
+}
+
+func writeCacheFile(cache *cachedTracks) error {
+	directory, err := getCacheDirectory()
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(directory, 0700); err != nil {
+		return err
+	}
+
+	content, err := json.Marshal(cache)
+
+	if err != nil {
+		return err
+	}
+
+	// The file is renamed at the end so another process never reads a half written cache
+	temporaryFile := filepath.Join(directory, cache.PlaylistId+".json.tmp")
+
+	if err := os.WriteFile(temporaryFile, content, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(temporaryFile, filepath.Join(directory, cache.PlaylistId+".json"))
+}
+
+func formatSize(size int64) string {
+	if size < 1024*1024 {
+		return fmt.Sprintf("%.1f KB", float64(size)/1024)
+	}
+
+	return fmt.Sprintf("%.1f MB", float64(size)/(1024*1024))
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/CarlosGMI/Playlistify/utils"
+)
+
+func TestCachedPages(t *testing.T) {
+	tracks := make([]track, utils.TracksLimit+50)
+
+	for i := range tracks {
+		tracks[i].Track = trackInfo{Id: fmt.Sprintf("id%d", i), Name: fmt.Sprintf("Track %d", i)}
+	}
+
+	pages := []tracksPage{{0, tracks[:utils.TracksLimit], nil}, {1, tracks[utils.TracksLimit:], nil}}
+	failedPages := []tracksPage{{0, tracks[:utils.TracksLimit], nil}, {1, nil, errors.New("failed")}}
+
+	tests := []struct {
+		name        string
+		stored      *playlist
+		storedPages []tracksPage
+		read        *playlist
+		refresh     bool
+		wantPages   []int
+	}{
+		{
+			name:        "same snapshot",
+			stored:      &playlist{Id: "p1", SnapshotId: "s1"},
+			storedPages: pages,
+			read:        &playlist{Id: "p1", SnapshotId: "s1"},
+			wantPages:   []int{utils.TracksLimit, 50},
+		},
+		{
+			name:        "another snapshot",
+			stored:      &playlist{Id: "p1", SnapshotId: "s1"},
+			storedPages: pages,
+			read:        &playlist{Id: "p1", SnapshotId: "s2"},
+		},
+		{
+			name:        "another playlist",
+			stored:      &playlist{Id: "p1", SnapshotId: "s1"},
+			storedPages: pages,
+			read:        &playlist{Id: "p2", SnapshotId: "s1"},
+		},
+		{
+			name:        "refresh",
+			stored:      &playlist{Id: "p1", SnapshotId: "s1"},
+			storedPages: pages,
+			read:        &playlist{Id: "p1", SnapshotId: "s1"},
+			refresh:     true,
+		},
+		{
+			name:        "failed page",
+			stored:      &playlist{Id: "p1", SnapshotId: "s1"},
+			storedPages: failedPages,
+			read:        &playlist{Id: "p1", SnapshotId: "s1"},
+		},
+		{
+			name:        "unknown snapshot",
+			stored:      &playlist{Id: "p1"},
+			storedPages: pages,
+			read:        &playlist{Id: "p1"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var pageSizes []int
+
+			t.Setenv("XDG_CACHE_HOME", t.TempDir())
+			setRefreshOption(t, test.refresh)
+			storeCachedPages(test.stored, test.storedPages)
+			cachedPages, ok := getCachedPages(test.read)
+
+			for i, page := range cachedPages {
+				if page.number != i || page.err != nil {
+					t.Errorf("the cached page %d is %d with error %v", i, page.number, page.err)
+				}
+
+				pageSizes = append(pageSizes, len(page.tracks))
+			}
+
+			if ok != (test.wantPages != nil) || fmt.Sprint(pageSizes) != fmt.Sprint(test.wantPages) {
+				t.Errorf("getCachedPages() = %v, %v, want %v", pageSizes, ok, test.wantPages)
+			}
+
+			if ok && cachedPages[1].tracks[49].Track.Id != tracks[len(tracks)-1].Track.Id {
+				t.Errorf("the last cached track is %q, want %q", cachedPages[1].tracks[49].Track.Id, tracks[len(tracks)-1].Track.Id)
+			}
+		})
+	}
+}
+
+func TestClearTracksCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	storeCachedPages(&playlist{Id: "p1", Name: "Road Trip", SnapshotId: "s1"}, []tracksPage{{0, make([]track, 3), nil}})
+
+	stats, err := GetTracksCacheStats()
+
+	if err != nil || len(stats.Results) != 1 || stats.Results[0][1] != "Road Trip" || stats.Results[0][2] != "3" {
+		t.Fatalf("GetTracksCacheStats() = %v, %v, want the 3 tracks of Road Trip", stats.Results, err)
+	}
+
+	if err := ClearTracksCache(); err != nil {
+		t.Fatalf("ClearTracksCache() error = %v", err)
+	}
+
+	if stats, err := GetTracksCacheStats(); err != nil || len(stats.Results) != 0 {
+		t.Errorf("GetTracksCacheStats() after clearing the cache = %v, %v", stats.Results, err)
+	}
+}
+
+func setRefreshOption(t *testing.T, refresh bool) {
+	previous := utils.Options.Refresh
+	utils.Options.Refresh = refresh
+
+	t.Cleanup(func() {
+		utils.Options.Refresh = previous
+	})
+}
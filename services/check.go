@@ -0,0 +1,293 @@
+package services
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/CarlosGMI/Playlistify/utils"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// checkFoundThreshold is the minimum score of a match to consider the entry found, and checkAmbiguityMargin the
+// score difference under which two different tracks are considered equally good matches
+const checkFoundThreshold = 0.9
+const checkAmbiguityMargin = 0.05
+
+type trackListEntry struct {
+	title  string
+	artist string
+}
+
+type entryMatch struct {
+	track playlistTrack
+	score float64
+}
+
+var titleColumns = []string{"title", "name", "track", "song"}
+var artistColumns = []string{"artist", "artists", "creator"}
+
+// CheckTrackListInPlaylist looks for every entry of a CSV, M3U or text file in the playlist and reports whether it
+// was found, not found or matched several tracks
+func CheckTrackListInPlaylist(playlistId string, fileName string) tea.Msg {
+	var message = TableResultsMsg{TableType: utils.CheckTable}
+	var found, notFound, ambiguous int
+	entries, err := readTrackList(fileName)
+
+	if err != nil {
+		return PlaylistsErrorMsg{err.Error()}
+	}
+
+	playlist, tracks, err := resolvePlaylistTracks(playlistId)
+
+	if err != nil {
+		return errorToMsg(err)
+	}
+
+	for _, entry := range entries {
+		status, matches := checkEntry(entry, tracks)
+		position, match, score := "", "", ""
+
+		switch status {
+		case utils.FoundStatus:
+			found++
+		case utils.NotFoundStatus:
+			notFound++
+		default:
+			ambiguous++
+		}
+
+		if len(matches) > 0 {
+			position = strconv.Itoa(matches[0].track.position)
+			match = describeMatches(matches)
+			score = fmt.Sprintf("%.2f", matches[0].score)
+		}
+
+		message.appendRow([]string{status, entry.String(), position, match, score})
+	}
+
+	message.Title = fmt.Sprintf(utils.CheckTitle, playlist.Name, found, notFound, ambiguous)
+
+	return message
+}
+
+// checkEntry uses the same fuzzy and Jaro-Winkler matching of the search, requiring both the title and the artist
+// (when the entry has one) to match. Occurrences of the same track only count once
+func checkEntry(entry trackListEntry, tracks []playlistTrack) (string, []entryMatch) {
+	var matches []entryMatch
+	var seenTracks = map[string]bool{}
+	title := strings.ToLower(entry.title)
+	artist := strings.ToLower(entry.artist)
+
+	for _, item := range tracks {
+		titleMatches, score := matchTerm(title, item.track.Name)
+
+		if normalizedScore := CalculateJaroWinkler(title, item.track.normalizedName()); normalizedScore > score {
+			score = normalizedScore
+		}
+
+		if !titleMatches {
+			continue
+		}
+
+		if artist != "" {
+			artistMatches, artistScore := matchTerm(artist, item.track.formattedArtists())
+
+			if !artistMatches {
+				continue
+			}
+
+			score = (score + artistScore) / 2
+		}
+
+		// Different releases of the same recording share the ISRC, so they are the same match
+		key := item.track.ExternalIds.Isrc
+
+		if key == "" {
+			key = item.track.Id
+		}
+
+		if key == "" {
+			key = strings.ToLower(item.track.Name + " - " + item.track.formattedArtists())
+		}
+
+		if !seenTracks[key] {
+			seenTracks[key] = true
+			matches = append(matches, entryMatch{item, score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	if len(matches) == 0 {
+		return utils.NotFoundStatus, matches
+	}
+
+	if matches[0].score >= checkFoundThreshold && (len(matches) == 1 || matches[0].score-matches[1].score >= checkAmbiguityMargin) {
+		return utils.FoundStatus, matches[:1]
+	}
+
+	return utils.AmbiguousStatus, matches
+}
+
+func describeMatches(matches []entryMatch) string {
+	var descriptions []string
+
+	for i, match := range matches {
+		if i == 3 {
+			descriptions = append(descriptions, fmt.Sprintf("and %d more", len(matches)-i))
+
+			break
+		}
+
+		descriptions = append(descriptions, fmt.Sprintf("#%d %s - %s", match.track.position, match.track.track.Name, match.track.track.formattedArtists()))
+	}
+
+	return strings.Join(descriptions, "; ")
+}
+
+// readTrackList reads the entries of a CSV file (with title and artist columns), an M3U playlist or a text file
+// with an "Artist - Title" entry per line
+func readTrackList(fileName string) ([]trackListEntry, error) {
+	file, err := os.Open(fileName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return readCSVTrackList(file)
+	case ".m3u", ".m3u8":
+		return readM3UTrackList(file)
+	default:
+		return readTextTrackList(file)
+	}
+}
+
+// readCSVTrackList uses the title and artist columns of the header. Without a known header the first column is
+// the title and the second one the artist
+func readCSVTrackList(reader io.Reader) ([]trackListEntry, error) {
+	var entries []trackListEntry
+	titleColumn, artistColumn := 0, 1
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	records, err := csvReader.ReadAll()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) > 0 {
+		header := records[0]
+		headerTitle := findColumn(header, titleColumns)
+		headerArtist := findColumn(header, artistColumns)
+
+		if headerTitle >= 0 {
+			titleColumn, artistColumn = headerTitle, headerArtist
+			records = records[1:]
+		}
+	}
+
+	for _, record := range records {
+		var entry trackListEntry
+
+		if titleColumn < len(record) {
+			entry.title = strings.TrimSpace(record[titleColumn])
+		}
+
+		if artistColumn >= 0 && artistColumn < len(record) {
+			entry.artist = strings.TrimSpace(record[artistColumn])
+		}
+
+		if entry.title != "" {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// readM3UTrackList takes the "Artist - Title" of the #EXTINF lines, or the file name of the entries without them
+func readM3UTrackList(reader io.Reader) ([]trackListEntry, error) {
+	var entries []trackListEntry
+	var pendingInfo string
+	scanner := bufio.NewScanner(reader)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case strings.HasPrefix(line, "#EXTINF:"):
+			if separator := strings.Index(line, ","); separator >= 0 {
+				pendingInfo = line[separator+1:]
+			}
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		default:
+			if pendingInfo == "" {
+				name := filepath.Base(strings.ReplaceAll(line, "\\", "/"))
+				pendingInfo = strings.TrimSuffix(name, filepath.Ext(name))
+			}
+
+			entries = append(entries, parseTrackListLine(pendingInfo))
+			pendingInfo = ""
+		}
+	}
+
+	return entries, scanner.Err()
+}
+
+func readTextTrackList(reader io.Reader) ([]trackListEntry, error) {
+	var entries []trackListEntry
+	scanner := bufio.NewScanner(reader)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line != "" && !strings.HasPrefix(line, "#") {
+			entries = append(entries, parseTrackListLine(line))
+		}
+	}
+
+	return entries, scanner.Err()
+}
+
+// parseTrackListLine splits an "Artist - Title" line, lines without a separator only have the title
+func parseTrackListLine(line string) trackListEntry {
+	if parts := strings.SplitN(line, " - ", 2); len(parts) == 2 {
+		return trackListEntry{title: strings.TrimSpace(parts[1]), artist: strings.TrimSpace(parts[0])}
+	}
+
+	return trackListEntry{title: strings.TrimSpace(line)}
+}
+
+func findColumn(header []string, names []string) int {
+	for i, column := range header {
+		for _, name := range names {
+			if strings.EqualFold(strings.TrimSpace(column), name) {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+func (entry trackListEntry) String() string {
+	if entry.artist == "" {
+		return entry.title
+	}
+
+	return entry.artist + " - " + entry.title
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/CarlosGMI/Playlistify/utils"
+)
+
+func TestCheckEntry(t *testing.T) {
+	tracks := []playlistTrack{
+		newPlaylistTrack(1, "id1", "ISRC1", "Numb", "Linkin Park"),
+		newPlaylistTrack(2, "id2", "ISRC2", "In the End", "Linkin Park"),
+		newPlaylistTrack(3, "id3", "ISRC3", "One", "U2"),
+		newPlaylistTrack(4, "id4", "ISRC4", "One", "Metallica"),
+		newPlaylistTrack(5, "id1", "ISRC1", "Numb", "Linkin Park"),
+		newPlaylistTrack(6, "id5", "ISRC2", "In the End", "Linkin Park"),
+		newPlaylistTrack(7, "id6", "ISRC6", "Yellow (Live)", "Coldplay"),
+	}
+
+	tests := []struct {
+		name          string
+		entry         trackListEntry
+		wantStatus    string
+		wantPositions []int
+	}{
+		{"title and artist", trackListEntry{"Numb", "Linkin Park"}, utils.FoundStatus, []int{1}},
+		{"another case", trackListEntry{"in the end", "linkin park"}, utils.FoundStatus, []int{2}},
+		{"decorated name", trackListEntry{"Yellow", "Coldplay"}, utils.FoundStatus, []int{7}},
+		{"artist tells the tracks apart", trackListEntry{"One", "Metallica"}, utils.FoundStatus, []int{4}},
+		{"title of several artists", trackListEntry{"One", ""}, utils.AmbiguousStatus, []int{3, 4}},
+		{"title of another artist", trackListEntry{"Numb", "U2"}, utils.NotFoundStatus, nil},
+		{"missing title", trackListEntry{"Clocks", "Coldplay"}, utils.NotFoundStatus, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var positions []int
+			status, matches := checkEntry(test.entry, tracks)
+
+			for _, match := range matches {
+				positions = append(positions, match.track.position)
+			}
+
+			if status != test.wantStatus || fmt.Sprint(positions) != fmt.Sprint(test.wantPositions) {
+				t.Errorf("checkEntry(%q) = %q %v, want %q %v", test.entry, status, positions, test.wantStatus, test.wantPositions)
+			}
+		})
+	}
+}
+
+func TestReadTrackList(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		content  string
+		want     []trackListEntry
+	}{
+		{
+			name:     "csv with header",
+			fileName: "tracks.csv",
+			content:  "Position,Artist,Title\n1,Linkin Park,Numb\n2, U2 , One \n3,Coldplay,\n",
+			want:     []trackListEntry{{"Numb", "Linkin Park"}, {"One", "U2"}},
+		},
+		{
+			name:     "csv with header without artist",
+			fileName: "tracks.CSV",
+			content:  "name\nNumb\n",
+			want:     []trackListEntry{{"Numb", ""}},
+		},
+		{
+			name:     "csv without header",
+			fileName: "tracks.csv",
+			content:  "Numb,Linkin Park\n\"One, Two\",U2,extra\nYellow\n",
+			want:     []trackListEntry{{"Numb", "Linkin Park"}, {"One, Two", "U2"}, {"Yellow", ""}},
+		},
+		{
+			name:     "m3u",
+			fileName: "tracks.m3u8",
+			content:  "#EXTM3U\n#PLAYLIST:Mix\n#EXTINF:185,Linkin Park - Numb\nspotify:track:id1\n\n#EXTINF:-1,One\nhttp://example.com/one.mp3\n",
+			want:     []trackListEntry{{"Numb", "Linkin Park"}, {"One", ""}},
+		},
+		{
+			name:     "m3u without extinf",
+			fileName: "tracks.m3u",
+			content:  "Music/Coldplay - Yellow.mp3\r\nC:\\Music\\U2 - One.flac\r\n",
+			want:     []trackListEntry{{"Yellow", "Coldplay"}, {"One", "U2"}},
+		},
+		{
+			name:     "text",
+			fileName: "tracks.txt",
+			content:  "# My favourites\nLinkin Park - Numb\n\n  Yellow  \nAC/DC - Back In Black - Remastered\n",
+			want:     []trackListEntry{{"Numb", "Linkin Park"}, {"Yellow", ""}, {"Back In Black - Remastered", "AC/DC"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fileName := filepath.Join(t.TempDir(), test.fileName)
+
+			if err := os.WriteFile(fileName, []byte(test.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := readTrackList(fileName)
+
+			if err != nil {
+				t.Fatalf("readTrackList() error = %v", err)
+			}
+
+			if fmt.Sprintf("%q", got) != fmt.Sprintf("%q", test.want) {
+				t.Errorf("readTrackList() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestReadCSVTrackListError(t *testing.T) {
+	if _, err := readCSVTrackList(strings.NewReader("\"Numb,Linkin Park\n")); err == nil {
+		t.Error("readCSVTrackList() with an unterminated quote didn't fail")
+	}
+}
@@ -0,0 +1,441 @@
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/CarlosGMI/Playlistify/utils"
+	"github.com/spf13/viper"
+	"github.com/zalando/go-keyring"
+	"golang.org/x/crypto/scrypt"
+)
+
+// credentials are the tokens of the logged in user
+type credentials struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	Expiration   int64  `json:"token_expiration"`
+}
+
+// credentialStore is where the credentials are kept between runs
+type credentialStore interface {
+	load() (*credentials, error)
+	save(credentials *credentials) error
+	clear() error
+}
+
+// keyringStore keeps the credentials in the system keyring: the Secret Service API over D-Bus on Linux, the
+// Keychain on macOS and the Credential Manager on Windows
+type keyringStore struct {
+	user string
+}
+
+// fileStore keeps the credentials in a file encrypted with AES-GCM, using a key derived from the passphrase
+type fileStore struct {
+	fileName   string
+	passphrase string
+}
+
+// plainStore keeps the credentials in plain text in the configuration file, like the first versions did
+type plainStore struct {
+	profile string
+}
+
+// encryptedCredentials is the content of the encrypted credentials file
+type encryptedCredentials struct {
+	Salt  []byte `json:"salt"`
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+// PassphrasePrompt asks the user for the passphrase of the encrypted credentials file, which is a new one when
+// create is enabled
+type PassphrasePrompt func(create bool) (string, error)
+
+var credentialsMutex sync.Mutex
+var currentCredentials *credentials
+var currentStore credentialStore
+
+// UnlockCredentials loads the credentials before any command runs, so the passphrase of the encrypted file can be
+// asked before the TUI takes over the terminal. The passphrase is only asked when the PLAYLISTIFY_PASSPHRASE
+// environment variable isn't set and there's a file to unlock, or a new one has to be created because the user is
+// logging in or the tokens of the configuration file have to be migrated
+func UnlockCredentials(prompt PassphrasePrompt, create bool) error {
+	credentialsMutex.Lock()
+	defer credentialsMutex.Unlock()
+
+	store, err := getCredentialStore()
+
+	if err != nil {
+		return err
+	}
+
+	if store, ok := store.(*fileStore); ok && store.passphrase == "" {
+		_, err = os.Stat(store.fileName)
+		exists := err == nil
+
+		if exists || create || hasPlainTokens() {
+			if store.passphrase, err = prompt(!exists); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err = loadCredentials()
+
+	return err
+}
+
+// getCredentials returns the credentials of the logged in user, which are empty when nobody is logged in
+func getCredentials() (credentials, error) {
+	credentialsMutex.Lock()
+	defer credentialsMutex.Unlock()
+
+	current, err := loadCredentials()
+
+	if err != nil {
+		return credentials{}, fmt.Errorf(utils.CredentialsError, err.Error())
+	}
+
+	return *current, nil
+}
+
+// setCredentials saves the credentials in the store. The cached ones are only replaced if they could be saved
+func setCredentials(newCredentials credentials) error {
+	credentialsMutex.Lock()
+	defer credentialsMutex.Unlock()
+
+	store, err := getCredentialStore()
+
+	if err != nil {
+		return err
+	}
+
+	if err := store.save(&newCredentials); err != nil {
+		return fmt.Errorf(utils.CredentialsError, err.Error())
+	}
+
+	currentCredentials = &newCredentials
+
+	return nil
+}
+
+// clearCredentials deletes the credentials from the store and from the configuration file, in case there are
+// tokens that weren't migrated
+func clearCredentials() error {
+	credentialsMutex.Lock()
+	defer credentialsMutex.Unlock()
+
+	store, err := getCredentialStore()
+
+	if err != nil {
+		return err
+	}
+
+	if err := store.clear(); err != nil {
+		return fmt.Errorf(utils.CredentialsError, err.Error())
+	}
+
+	currentCredentials = &credentials{}
+
+	if _, ok := store.(plainStore); !ok && hasPlainTokens() {
+		return utils.RemoveSettings(getPlainTokenKeys(utils.ActiveProfile())...)
+	}
+
+	return nil
+}
+
+// loadCredentials reads the credentials from the store the first time. Tokens found in the configuration file are
+// moved to the store when it's empty, and removed from the file either way
+func loadCredentials() (*credentials, error) {
+	if currentCredentials != nil {
+		return currentCredentials, nil
+	}
+
+	store, err := getCredentialStore()
+
+	if err != nil {
+		return nil, err
+	}
+
+	loaded, err := store.load()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := store.(plainStore); !ok && hasPlainTokens() {
+		if loaded.RefreshToken == "" {
+			plainCredentials, _ := plainStore{utils.ActiveProfile()}.load()
+
+			if err := store.save(plainCredentials); err != nil {
+				return nil, err
+			}
+
+			loaded = plainCredentials
+			fmt.Fprintf(os.Stderr, utils.TokensMigratedMessage+"\n", viper.ConfigFileUsed())
+		} else {
+			fmt.Fprintf(os.Stderr, utils.TokensRemovedMessage+"\n", viper.ConfigFileUsed())
+		}
+
+		if err := utils.RemoveSettings(getPlainTokenKeys(utils.ActiveProfile())...); err != nil {
+			return nil, err
+		}
+	}
+
+	currentCredentials = loaded
+
+	return currentCredentials, nil
+}
+
+// getCredentialStore returns the store of the active profile
+func getCredentialStore() (credentialStore, error) {
+	var err error
+
+	if currentStore == nil {
+		currentStore, err = newCredentialStore(utils.ActiveProfile())
+	}
+
+	return currentStore, err
+}
+
+// newCredentialStore returns the configured store for the profile. The auto store uses the keyring when it's
+// available and the encrypted file otherwise
+func newCredentialStore(profile string) (credentialStore, error) {
+	user := utils.KeyringUser(profile)
+	fileName, err := getCredentialsFile(profile)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch utils.CredentialStore() {
+	case utils.AutoStore:
+		if _, err := keyring.Get(utils.KeyringService, user); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+			log.Println("the keyring is not available, using the encrypted file:", err)
+
+			return &fileStore{fileName, utils.Passphrase()}, nil
+		}
+
+		return keyringStore{user}, nil
+	case utils.KeyringStore:
+		if _, err := keyring.Get(utils.KeyringService, user); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+			return nil, fmt.Errorf(utils.KeyringUnavailableError, err.Error())
+		}
+
+		return keyringStore{user}, nil
+	case utils.FileStore:
+		return &fileStore{fileName, utils.Passphrase()}, nil
+	case utils.PlainStore:
+		return plainStore{profile}, nil
+	default:
+		return nil, fmt.Errorf(utils.InvalidCredentialStoreError, utils.CredentialStore())
+	}
+}
+
+// hasPlainTokens checks if the configuration file has tokens of the active profile
+func hasPlainTokens() bool {
+	return viper.GetString(utils.ProfileKey("token")) != "" || viper.GetString(utils.ProfileKey("refresh_token")) != ""
+}
+
+// getPlainTokenKeys are the settings the plain store saves the tokens of the profile in
+func getPlainTokenKeys(profile string) []string {
+	return []string{
+		utils.ProfileSettingKey(profile, "token"),
+		utils.ProfileSettingKey(profile, "refresh_token"),
+		utils.ProfileSettingKey(profile, "token_expiration"),
+	}
+}
+
+func (store keyringStore) load() (*credentials, error) {
+	var loaded = new(credentials)
+	content, err := keyring.Get(utils.KeyringService, store.user)
+
+	if errors.Is(err, keyring.ErrNotFound) {
+		return loaded, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(content), loaded); err != nil {
+		return nil, err
+	}
+
+	return loaded, nil
+}
+
+func (store keyringStore) save(credentials *credentials) error {
+	content, err := json.Marshal(credentials)
+
+	if err != nil {
+		return err
+	}
+
+	return keyring.Set(utils.KeyringService, store.user, string(content))
+}
+
+func (store keyringStore) clear() error {
+	if err := keyring.Delete(utils.KeyringService, store.user); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+func (store *fileStore) load() (*credentials, error) {
+	var loaded = new(credentials)
+	var encrypted encryptedCredentials
+	content, err := os.ReadFile(store.fileName)
+
+	if os.IsNotExist(err) {
+		return loaded, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if store.passphrase == "" {
+		return nil, errors.New(utils.MissingPassphraseError)
+	}
+
+	if err := json.Unmarshal(content, &encrypted); err != nil {
+		return nil, err
+	}
+
+	aead, err := store.getCipher(encrypted.Salt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := aead.Open(nil, encrypted.Nonce, encrypted.Data, nil)
+
+	if err != nil {
+		return nil, errors.New(utils.WrongPassphraseError)
+	}
+
+	if err := json.Unmarshal(data, loaded); err != nil {
+		return nil, err
+	}
+
+	return loaded, nil
+}
+
+// save encrypts the credentials with a new salt and nonce every time
+func (store *fileStore) save(credentials *credentials) error {
+	var encrypted = encryptedCredentials{Salt: make([]byte, 16)}
+
+	if store.passphrase == "" {
+		return errors.New(utils.MissingPassphraseError)
+	}
+
+	data, err := json.Marshal(credentials)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := rand.Read(encrypted.Salt); err != nil {
+		return err
+	}
+
+	aead, err := store.getCipher(encrypted.Salt)
+
+	if err != nil {
+		return err
+	}
+
+	encrypted.Nonce = make([]byte, aead.NonceSize())
+
+	if _, err := rand.Read(encrypted.Nonce); err != nil {
+		return err
+	}
+
+	encrypted.Data = aead.Seal(nil, encrypted.Nonce, data, nil)
+	content, err := json.Marshal(encrypted)
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(store.fileName), 0700); err != nil {
+		return err
+	}
+
+	// Like the journal, the file is renamed at the end so it's never left half written
+	if err := os.WriteFile(store.fileName+".tmp", content, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(store.fileName+".tmp", store.fileName)
+}
+
+func (store *fileStore) clear() error {
+	if err := os.Remove(store.fileName); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// getCipher derives the key from the passphrase with scrypt, using the recommended parameters for interactive logins
+func (store *fileStore) getCipher(salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(store.passphrase), salt, 1<<15, 8, 1, 32)
+
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func (store plainStore) load() (*credentials, error) {
+	return &credentials{
+		AccessToken:  viper.GetString(utils.ProfileSettingKey(store.profile, "token")),
+		RefreshToken: viper.GetString(utils.ProfileSettingKey(store.profile, "refresh_token")),
+		Expiration:   viper.GetInt64(utils.ProfileSettingKey(store.profile, "token_expiration")),
+	}, nil
+}
+
+func (store plainStore) save(credentials *credentials) error {
+	viper.Set(utils.ProfileSettingKey(store.profile, "token"), credentials.AccessToken)
+	viper.Set(utils.ProfileSettingKey(store.profile, "token_expiration"), credentials.Expiration)
+	viper.Set(utils.ProfileSettingKey(store.profile, "refresh_token"), credentials.RefreshToken)
+
+	return viper.WriteConfig()
+}
+
+func (store plainStore) clear() error {
+	for _, key := range getPlainTokenKeys(store.profile) {
+		viper.Set(key, "")
+	}
+
+	return viper.WriteConfig()
+}
+
+func getCredentialsFile(profile string) (string, error) {
+	directory, err := os.UserConfigDir()
+
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(utils.ProfileDirectory(filepath.Join(directory, "playlistify"), profile), utils.CredentialsFile), nil
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/CarlosGMI/Playlistify/utils"
+	"github.com/spf13/viper"
+)
+
+// memoryStore keeps the credentials of the tests, so they don't reach the keyring of the machine
+type memoryStore struct {
+	credentials credentials
+}
+
+func TestFileStore(t *testing.T) {
+	var saved = credentials{AccessToken: "access", RefreshToken: "refresh", Expiration: 1700000000}
+
+	fileName := filepath.Join(t.TempDir(), utils.CredentialsFile)
+	store := &fileStore{fileName, "secret"}
+
+	if loaded, err := store.load(); err != nil || *loaded != (credentials{}) {
+		t.Fatalf("load() without a file = %v, %v, want empty credentials", loaded, err)
+	}
+
+	if err := store.save(&saved); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+
+	if loaded, err := store.load(); err != nil || *loaded != saved {
+		t.Errorf("load() = %v, %v, want %v", loaded, err, saved)
+	}
+
+	if _, err := (&fileStore{fileName, "wrong"}).load(); err == nil || err.Error() != utils.WrongPassphraseError {
+		t.Errorf("load() with a wrong passphrase error = %v, want %q", err, utils.WrongPassphraseError)
+	}
+
+	if _, err := (&fileStore{fileName, ""}).load(); err == nil || err.Error() != utils.MissingPassphraseError {
+		t.Errorf("load() without a passphrase error = %v, want %q", err, utils.MissingPassphraseError)
+	}
+
+	if err := (&fileStore{fileName, ""}).save(&saved); err == nil || err.Error() != utils.MissingPassphraseError {
+		t.Errorf("save() without a passphrase error = %v, want %q", err, utils.MissingPassphraseError)
+	}
+
+	if err := store.clear(); err != nil {
+		t.Fatalf("clear() error = %v", err)
+	}
+
+	if loaded, err := store.load(); err != nil || *loaded != (credentials{}) {
+		t.Errorf("load() after clear() = %v, %v, want empty credentials", loaded, err)
+	}
+}
+
+func TestLoadCredentialsMigration(t *testing.T) {
+	var plainTokens = credentials{AccessToken: "plain", RefreshToken: "plain refresh", Expiration: 1700000000}
+
+	tests := []struct {
+		name   string
+		stored credentials
+		want   credentials
+	}{
+		{"empty store", credentials{}, plainTokens},
+		{"logged in store", credentials{AccessToken: "stored", RefreshToken: "stored refresh"}, credentials{AccessToken: "stored", RefreshToken: "stored refresh"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := &memoryStore{test.stored}
+			useConfigFile(t, `{"profiles": {"default": {"user_id": "user", "token": "plain", "refresh_token": "plain refresh", "token_expiration": 1700000000}}}`)
+			useTestCredentials(t, credentials{})
+			currentStore, currentCredentials = store, nil
+
+			loaded, err := loadCredentials()
+
+			if err != nil || *loaded != test.want {
+				t.Fatalf("loadCredentials() = %v, %v, want %v", loaded, err, test.want)
+			}
+
+			if store.credentials != test.want {
+				t.Errorf("the stored credentials are %v, want %v", store.credentials, test.want)
+			}
+
+			if hasPlainTokens() || viper.GetString(utils.ProfileKey("user_id")) != "user" {
+				t.Errorf("the configuration file has the settings %v, want only the user_id", viper.AllSettings())
+			}
+		})
+	}
+}
+
+// useTestCredentials replaces the credential store with one in memory that has the credentials
+func useTestCredentials(t *testing.T, testCredentials credentials) {
+	previousStore, previousCredentials := currentStore, currentCredentials
+	currentStore, currentCredentials = &memoryStore{testCredentials}, &testCredentials
+
+	t.Cleanup(func() {
+		currentStore, currentCredentials = previousStore, previousCredentials
+	})
+}
+
+// useConfigFile makes viper read the configuration from a temporary file with the content
+func useConfigFile(t *testing.T, content string) {
+	fileName := filepath.Join(t.TempDir(), ".playlistify.json")
+
+	if err := os.WriteFile(fileName, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	viper.SetConfigFile(fileName)
+
+	if err := viper.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(viper.Reset)
+}
+
+func (store *memoryStore) load() (*credentials, error) {
+	loaded := store.credentials
+
+	return &loaded, nil
+}
+
+func (store *memoryStore) save(credentials *credentials) error {
+	store.credentials = *credentials
+
+	return nil
+}
+
+func (store *memoryStore) clear() error {
+	store.credentials = credentials{}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/CarlosGMI/Playlistify/utils"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/viper"
+)
+
+// RemoveDuplicatesFromPlaylist lists the occurrence of every group of duplicates that is kept (the first one) and
+// the ones that are removed. Nothing is removed in a dry run, otherwise the removal is returned as a
+// ConfirmationMsg so it only happens once the user confirms it
+func RemoveDuplicatesFromPlaylist(playlistId string, includeSimilar bool, dryRun bool) tea.Msg {
+	var message = TableResultsMsg{TableType: utils.DedupeTable}
+	var removals []playlistTrack
+	var removalRows = map[int][]string{}
+	playlist, tracks, err := resolvePlaylistTracks(playlistId)
+
+	if err != nil {
+		return errorToMsg(err)
+	}
+
+	if !isOwnPlaylist(playlist, viper.GetString(utils.ProfileKey("user_id"))) {
+		return PlaylistsErrorMsg{fmt.Sprintf(utils.NotEditablePlaylistError, playlist.Name)}
+	}
+
+	for group, cluster := range findDuplicates(tracks, includeSimilar) {
+		for i, item := range cluster.tracks {
+			row := []string{utils.RemoveAction, strconv.Itoa(group + 1), strconv.Itoa(item.position), item.track.Name, item.track.formattedArtists(), cluster.reasons[i]}
+
+			if i == 0 {
+				row[0], row[5] = utils.KeepAction, utils.FirstOccurrenceReason
+			} else {
+				removals = append(removals, item)
+				removalRows[item.position] = row
+			}
+
+			message.appendRow(row)
+		}
+	}
+
+	switch {
+	case len(removals) == 0:
+		message.Title = fmt.Sprintf(utils.NoDuplicatesTitle, playlist.Name)
+	case dryRun:
+		message.Title = fmt.Sprintf(utils.DedupeDryRunTitle, len(removals), playlist.Name)
+	default:
+		message.Title = fmt.Sprintf(utils.DedupeConfirmationTitle, len(removals), playlist.Name)
+
+		return ConfirmationMsg{
+			TableResultsMsg: message,
+			ProgressText:    "Removing duplicates...",
+			Action: func() tea.Msg {
+				return removeDuplicates(playlist, removals, removalRows)
+			},
+		}
+	}
+
+	return message
+}
+
+// removeDuplicates removes the tracks and records the removal in the journal, even when only some of the batches
+// could be removed
+func removeDuplicates(playlist *playlist, removals []playlistTrack, removalRows map[int][]string) tea.Msg {
+	var message = TableResultsMsg{TableType: utils.DedupeTable}
+	var entry = journalEntry{
+		Operation:      utils.DedupeOperation,
+		PlaylistId:     playlist.Id,
+		PlaylistName:   playlist.Name,
+		SnapshotBefore: playlist.SnapshotId,
+	}
+	removed, err := removeTracks(playlist, removals)
+
+	if len(removed) > 0 {
+		entry.SnapshotAfter = playlist.SnapshotId
+		entry.Removed = journalTracks(removed)
+
+		if journalErr := recordOperation(&entry); journalErr != nil {
+			return PlaylistsErrorMsg{fmt.Sprintf(utils.JournalError, len(removed), playlist.Name, journalErr.Error())}
+		}
+	}
+
+	if err != nil && len(removed) == 0 {
+		return PlaylistsErrorMsg{err.Error()}
+	}
+
+	if err != nil {
+		return PlaylistsErrorMsg{fmt.Sprintf(utils.PartialRemovalError, err.Error(), len(removed), len(removals), entry.Id)}
+	}
+
+	sort.Slice(removed, func(i, j int) bool {
+		return removed[i].position < removed[j].position
+	})
+
+	for _, item := range removed {
+		row := append([]string(nil), removalRows[item.position]...)
+		row[0] = utils.RemovedAction
+		message.appendRow(row)
+	}
+
+	message.Title = fmt.Sprintf(utils.DedupeTitle, len(removed), playlist.Name, entry.Id)
+
+	return message
+}
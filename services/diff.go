@@ -0,0 +1,130 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/CarlosGMI/Playlistify/utils"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type trackPair struct {
+	first  playlistTrack
+	second playlistTrack
+	reason string
+}
+
+// ComparePlaylists lists the tracks that are only in the first playlist, only in the second one and in both
+func ComparePlaylists(firstPlaylistId string, secondPlaylistId string) tea.Msg {
+	var message = TableResultsMsg{TableType: utils.DiffTable}
+	firstPlaylist, firstTracks, err := resolvePlaylistTracks(firstPlaylistId)
+
+	if err != nil {
+		return errorToMsg(err)
+	}
+
+	secondPlaylist, secondTracks, err := resolvePlaylistTracks(secondPlaylistId)
+
+	if err != nil {
+		return errorToMsg(err)
+	}
+
+	pairs, onlyInFirst, onlyInSecond := matchTracks(firstTracks, secondTracks)
+
+	for _, item := range onlyInFirst {
+		message.appendRow([]string{utils.OnlyInAStatus, strconv.Itoa(item.position), "", item.track.Name, item.track.formattedArtists(), ""})
+	}
+
+	for _, item := range onlyInSecond {
+		message.appendRow([]string{utils.OnlyInBStatus, "", strconv.Itoa(item.position), item.track.Name, item.track.formattedArtists(), ""})
+	}
+
+	for _, pair := range pairs {
+		firstPosition := strconv.Itoa(pair.first.position)
+		secondPosition := strconv.Itoa(pair.second.position)
+		message.appendRow([]string{utils.InBothStatus, firstPosition, secondPosition, pair.first.track.Name, pair.first.track.formattedArtists(), pair.reason})
+	}
+
+	message.Title = fmt.Sprintf(utils.DiffTitle, firstPlaylist.Name, secondPlaylist.Name, len(onlyInFirst), len(onlyInSecond), len(pairs))
+
+	return message
+}
+
+// matchTracks pairs every track of the first list with one of the second list. Tracks are paired by ID first, and
+// the remaining ones by ISRC or similar name and artist, so re-uploaded versions of a track are also paired. Like
+// findDuplicates, names are only compared with the tracks of the same main artist
+func matchTracks(firstTracks []playlistTrack, secondTracks []playlistTrack) ([]trackPair, []playlistTrack, []playlistTrack) {
+	var pairs []trackPair
+	var onlyInFirst, onlyInSecond []playlistTrack
+	var unpairedFirst []int
+	paired := make([]bool, len(secondTracks))
+	secondTracksById := map[string][]int{}
+	secondTracksByIsrc := map[string][]int{}
+	secondTracksByArtist := map[string][]int{}
+
+	for i, item := range secondTracks {
+		secondTracksById[item.track.Id] = append(secondTracksById[item.track.Id], i)
+		secondTracksByIsrc[item.track.ExternalIds.Isrc] = append(secondTracksByIsrc[item.track.ExternalIds.Isrc], i)
+		secondTracksByArtist[item.track.mainArtist()] = append(secondTracksByArtist[item.track.mainArtist()], i)
+	}
+
+	for i, item := range firstTracks {
+		if item.track.Id != "" {
+			if index, ok := takeUnpaired(secondTracksById[item.track.Id], paired); ok {
+				pairs = append(pairs, trackPair{item, secondTracks[index], utils.SameTrackReason})
+
+				continue
+			}
+		}
+
+		unpairedFirst = append(unpairedFirst, i)
+	}
+
+	for _, i := range unpairedFirst {
+		var pair *trackPair
+		item := firstTracks[i]
+
+		if item.track.ExternalIds.Isrc != "" {
+			if index, ok := takeUnpaired(secondTracksByIsrc[item.track.ExternalIds.Isrc], paired); ok {
+				pair = &trackPair{item, secondTracks[index], utils.SameIsrcReason}
+			}
+		}
+
+		for _, index := range secondTracksByArtist[item.track.mainArtist()] {
+			if pair != nil || paired[index] {
+				continue
+			}
+
+			if reason, isDuplicate := getDuplicateReason(item.track, secondTracks[index].track, true); isDuplicate {
+				paired[index] = true
+				pair = &trackPair{item, secondTracks[index], reason}
+			}
+		}
+
+		if pair != nil {
+			pairs = append(pairs, *pair)
+		} else {
+			onlyInFirst = append(onlyInFirst, item)
+		}
+	}
+
+	for index, item := range secondTracks {
+		if !paired[index] {
+			onlyInSecond = append(onlyInSecond, item)
+		}
+	}
+
+	return pairs, onlyInFirst, onlyInSecond
+}
+
+func takeUnpaired(indexes []int, paired []bool) (int, bool) {
+	for _, index := range indexes {
+		if !paired[index] {
+			paired[index] = true
+
+			return index, true
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/CarlosGMI/Playlistify/utils"
+)
+
+func TestMatchTracks(t *testing.T) {
+	tests := []struct {
+		name             string
+		first            []playlistTrack
+		second           []playlistTrack
+		wantPairs        []string
+		wantOnlyInFirst  []int
+		wantOnlyInSecond []int
+	}{
+		{
+			name: "same tracks in another order",
+			first: []playlistTrack{
+				newPlaylistTrack(1, "id1", "ISRC1", "Numb", "Linkin Park"),
+				newPlaylistTrack(2, "id2", "ISRC2", "Faint", "Linkin Park"),
+			},
+			second: []playlistTrack{
+				newPlaylistTrack(1, "id2", "ISRC2", "Faint", "Linkin Park"),
+				newPlaylistTrack(2, "id1", "ISRC1", "Numb", "Linkin Park"),
+			},
+			wantPairs: []string{"1-2 " + utils.SameTrackReason, "2-1 " + utils.SameTrackReason},
+		},
+		{
+			name: "occurrences are paired once",
+			first: []playlistTrack{
+				newPlaylistTrack(1, "id1", "ISRC1", "Numb", "Linkin Park"),
+				newPlaylistTrack(2, "id1", "ISRC1", "Numb", "Linkin Park"),
+			},
+			second: []playlistTrack{
+				newPlaylistTrack(1, "id1", "ISRC1", "Numb", "Linkin Park"),
+			},
+			wantPairs:       []string{"1-1 " + utils.SameTrackReason},
+			wantOnlyInFirst: []int{2},
+		},
+		{
+			name: "re-uploaded and remastered tracks",
+			first: []playlistTrack{
+				newPlaylistTrack(1, "id1", "ISRC1", "Numb", "Linkin Park"),
+				newPlaylistTrack(2, "id2", "ISRC2", "Faint", "Linkin Park"),
+			},
+			second: []playlistTrack{
+				newPlaylistTrack(1, "id3", "ISRC1", "Numb", "Linkin Park"),
+				newPlaylistTrack(2, "id4", "ISRC4", "Faint - 2023 Remaster", "Linkin Park"),
+			},
+			wantPairs: []string{
+				"1-1 " + utils.SameIsrcReason,
+				"2-2 " + fmt.Sprintf(utils.SimilarTrackReason, 1.0),
+			},
+		},
+		{
+			name: "an exact match is preferred over a similar one",
+			first: []playlistTrack{
+				newPlaylistTrack(1, "id5", "ISRC5", "Numb (Live)", "Linkin Park"),
+				newPlaylistTrack(2, "id1", "ISRC1", "Numb", "Linkin Park"),
+			},
+			second: []playlistTrack{
+				newPlaylistTrack(1, "id1", "ISRC1", "Numb", "Linkin Park"),
+			},
+			wantPairs:       []string{"2-1 " + utils.SameTrackReason},
+			wantOnlyInFirst: []int{1},
+		},
+		{
+			name: "same names of other artists or without artists",
+			first: []playlistTrack{
+				newPlaylistTrack(1, "id1", "ISRC1", "Numb", "Linkin Park"),
+				newPlaylistTrack(2, "", "", "Intro", ""),
+			},
+			second: []playlistTrack{
+				newPlaylistTrack(1, "id2", "ISRC2", "Numb", "U2"),
+				newPlaylistTrack(2, "", "", "Intro", ""),
+			},
+			wantOnlyInFirst:  []int{1, 2},
+			wantOnlyInSecond: []int{1, 2},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var pairs []string
+			var onlyInFirstPositions, onlyInSecondPositions []int
+			pairsResult, onlyInFirst, onlyInSecond := matchTracks(test.first, test.second)
+
+			for _, pair := range pairsResult {
+				pairs = append(pairs, fmt.Sprintf("%d-%d %s", pair.first.position, pair.second.position, pair.reason))
+			}
+
+			for _, item := range onlyInFirst {
+				onlyInFirstPositions = append(onlyInFirstPositions, item.position)
+			}
+
+			for _, item := range onlyInSecond {
+				onlyInSecondPositions = append(onlyInSecondPositions, item.position)
+			}
+
+			if fmt.Sprint(pairs) != fmt.Sprint(test.wantPairs) {
+				t.Errorf("matchTracks() pairs = %v, want %v", pairs, test.wantPairs)
+			}
+
+			if fmt.Sprint(onlyInFirstPositions) != fmt.Sprint(test.wantOnlyInFirst) {
+				t.Errorf("matchTracks() only in the first = %v, want %v", onlyInFirstPositions, test.wantOnlyInFirst)
+			}
+
+			if fmt.Sprint(onlyInSecondPositions) != fmt.Sprint(test.wantOnlyInSecond) {
+				t.Errorf("matchTracks() only in the second = %v, want %v", onlyInSecondPositions, test.wantOnlyInSecond)
+			}
+		})
+	}
+}
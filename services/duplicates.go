@@ -0,0 +1,192 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/CarlosGMI/Playlistify/utils"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// duplicateNameThreshold is the minimum Jaro-Winkler score between the names of two tracks of the same main artist
+// to consider them duplicates
+const duplicateNameThreshold = 0.92
+
+// trackNameDecorations matches the parts of a name that usually change between versions of the same track, like
+// "(Live)", "[Remastered]" or "- 2011 Remaster"
+var trackNameDecorations = regexp.MustCompile(`\(.*?\)|\[.*?\]|\s-\s.*$`)
+
+type duplicateCluster struct {
+	tracks []playlistTrack
+	// reasons explains why every track of the cluster is a duplicate, the first one is always empty
+	reasons []string
+}
+
+func FindDuplicatesInPlaylist(playlistId string, includeSimilar bool) tea.Msg {
+	var message = TableResultsMsg{TableType: utils.DuplicatesTable}
+	playlist, tracks, err := resolvePlaylistTracks(playlistId)
+
+	if err != nil {
+		return errorToMsg(err)
+	}
+
+	clusters := findDuplicates(tracks, includeSimilar)
+	message.Title = fmt.Sprintf(utils.DuplicatesTitle, playlist.Name, len(clusters))
+
+	for group, cluster := range clusters {
+		for i, item := range cluster.tracks {
+			reason := cluster.reasons[i]
+
+			if i == 0 {
+				reason = utils.FirstOccurrenceReason
+			}
+
+			row := []string{strconv.Itoa(group + 1), strconv.Itoa(item.position), item.track.Name, item.track.formattedArtists(), reason}
+			message.appendRow(row)
+		}
+	}
+
+	return message
+}
+
+// findDuplicates groups the tracks that share the same ID or ISRC and, when includeSimilar is enabled, the tracks
+// of the same main artist with similar names. Tracks are only compared with the ones of the same main artist to
+// keep the number of comparisons low in big playlists
+func findDuplicates(tracks []playlistTrack, includeSimilar bool) []duplicateCluster {
+	var clusters []duplicateCluster
+	var clusterIndexes = map[int]int{}
+	parents := make([]int, len(tracks))
+	reasons := make([]string, len(tracks))
+	tracksById := map[string]int{}
+	tracksByIsrc := map[string]int{}
+	tracksByArtist := map[string][]int{}
+
+	for i := range parents {
+		parents[i] = i
+	}
+
+	for i, item := range tracks {
+		if index, ok := tracksById[item.track.Id]; ok && item.track.Id != "" {
+			joinClusters(parents, reasons, index, i, utils.SameTrackReason)
+		} else {
+			tracksById[item.track.Id] = i
+		}
+
+		if index, ok := tracksByIsrc[item.track.ExternalIds.Isrc]; ok && item.track.ExternalIds.Isrc != "" {
+			joinClusters(parents, reasons, index, i, utils.SameIsrcReason)
+		} else {
+			tracksByIsrc[item.track.ExternalIds.Isrc] = i
+		}
+
+		if !includeSimilar {
+			continue
+		}
+
+		artist := item.track.mainArtist()
+
+		for _, index := range tracksByArtist[artist] {
+			if findCluster(parents, index) == findCluster(parents, i) {
+				continue
+			}
+
+			if reason, isDuplicate := getDuplicateReason(tracks[index].track, item.track, true); isDuplicate {
+				joinClusters(parents, reasons, index, i, reason)
+			}
+		}
+
+		tracksByArtist[artist] = append(tracksByArtist[artist], i)
+	}
+
+	for i, item := range tracks {
+		root := findCluster(parents, i)
+		index, ok := clusterIndexes[root]
+
+		if !ok {
+			index = len(clusters)
+			clusterIndexes[root] = index
+			clusters = append(clusters, duplicateCluster{})
+		}
+
+		clusters[index].tracks = append(clusters[index].tracks, item)
+		clusters[index].reasons = append(clusters[index].reasons, reasons[i])
+	}
+
+	return removeUniqueTracks(clusters)
+}
+
+// removeUniqueTracks removes the clusters with a single track and clears the reason of the first track of every
+// cluster, which is the one the others are duplicates of
+func removeUniqueTracks(clusters []duplicateCluster) []duplicateCluster {
+	var duplicates []duplicateCluster
+
+	for _, cluster := range clusters {
+		if len(cluster.tracks) < 2 {
+			continue
+		}
+
+		cluster.reasons[0] = ""
+		duplicates = append(duplicates, cluster)
+	}
+
+	return duplicates
+}
+
+// getDuplicateReason tells if two tracks are duplicates and why. Similar names are only compared when includeSimilar
+// is enabled
+func getDuplicateReason(first trackInfo, second trackInfo, includeSimilar bool) (string, bool) {
+	if first.Id != "" && first.Id == second.Id {
+		return utils.SameTrackReason, true
+	}
+
+	if first.ExternalIds.Isrc != "" && first.ExternalIds.Isrc == second.ExternalIds.Isrc {
+		return utils.SameIsrcReason, true
+	}
+
+	// Tracks without artists, like some local files, can't be told apart by their names alone
+	if includeSimilar && first.mainArtist() != "" && first.mainArtist() == second.mainArtist() {
+		score := CalculateJaroWinkler(first.normalizedName(), second.normalizedName())
+
+		if score >= duplicateNameThreshold {
+			return fmt.Sprintf(utils.SimilarTrackReason, score), true
+		}
+	}
+
+	return "", false
+}
+
+func findCluster(parents []int, index int) int {
+	for parents[index] != index {
+		parents[index] = parents[parents[index]]
+		index = parents[index]
+	}
+
+	return index
+}
+
+// joinClusters merges the clusters of two tracks and records why they were joined. A track keeps the first reason
+// it got, which links it to a track of the cluster even when it's the other track that was found later
+func joinClusters(parents []int, reasons []string, first int, second int, reason string) {
+	parents[findCluster(parents, second)] = findCluster(parents, first)
+
+	for _, index := range []int{first, second} {
+		if reasons[index] == "" {
+			reasons[index] = reason
+		}
+	}
+}
+
+func (info trackInfo) mainArtist() string {
+	if len(info.Artists) == 0 {
+		return ""
+	}
+
+	return strings.ToLower(strings.TrimSpace(info.Artists[0].Name))
+}
+
+func (info trackInfo) normalizedName() string {
+	name := trackNameDecorations.ReplaceAllString(info.Name, "")
+
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
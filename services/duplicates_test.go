@@ -0,0 +1,181 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/CarlosGMI/Playlistify/utils"
+)
+
+func TestGetDuplicateReason(t *testing.T) {
+	tests := []struct {
+		name           string
+		first          trackInfo
+		second         trackInfo
+		includeSimilar bool
+		wantReason     string
+		want           bool
+	}{
+		{
+			"same id",
+			newTrackInfo("id1", "ISRC1", "Numb", "Linkin Park"),
+			newTrackInfo("id1", "ISRC1", "Numb", "Linkin Park"),
+			true, utils.SameTrackReason, true,
+		},
+		{
+			"same isrc",
+			newTrackInfo("id1", "ISRC1", "Numb", "Linkin Park"),
+			newTrackInfo("id2", "ISRC1", "Numb", "Linkin Park"),
+			true, utils.SameIsrcReason, true,
+		},
+		{
+			"remastered version",
+			newTrackInfo("id1", "ISRC1", "Numb", "Linkin Park"),
+			newTrackInfo("id2", "ISRC2", "Numb - 2023 Remaster", "Linkin Park"),
+			true, fmt.Sprintf(utils.SimilarTrackReason, 1.0), true,
+		},
+		{
+			"live version with another case",
+			newTrackInfo("id1", "ISRC1", "In the End", "Linkin Park"),
+			newTrackInfo("id2", "ISRC2", "In The End (Live)", "linkin park"),
+			true, fmt.Sprintf(utils.SimilarTrackReason, 1.0), true,
+		},
+		{
+			"same name of another artist",
+			newTrackInfo("id1", "ISRC1", "Numb", "Linkin Park"),
+			newTrackInfo("id2", "ISRC2", "Numb", "U2"),
+			true, "", false,
+		},
+		{
+			"different names of the same artist",
+			newTrackInfo("id1", "ISRC1", "Numb", "Linkin Park"),
+			newTrackInfo("id2", "ISRC2", "Faint", "Linkin Park"),
+			true, "", false,
+		},
+		{
+			"same name without artists",
+			newTrackInfo("", "", "Intro", ""),
+			newTrackInfo("", "", "Intro", ""),
+			true, "", false,
+		},
+		{
+			"empty ids and isrcs",
+			newTrackInfo("", "", "Numb", "Linkin Park"),
+			newTrackInfo("", "", "Faint", "Linkin Park"),
+			true, "", false,
+		},
+		{
+			"remastered version without similar names",
+			newTrackInfo("id1", "ISRC1", "Numb", "Linkin Park"),
+			newTrackInfo("id2", "ISRC2", "Numb - 2023 Remaster", "Linkin Park"),
+			false, "", false,
+		},
+		{
+			"same isrc without similar names",
+			newTrackInfo("id1", "ISRC1", "Numb", "Linkin Park"),
+			newTrackInfo("id2", "ISRC1", "Numb", "Linkin Park"),
+			false, utils.SameIsrcReason, true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reason, ok := getDuplicateReason(test.first, test.second, test.includeSimilar)
+
+			if reason != test.wantReason || ok != test.want {
+				t.Errorf("getDuplicateReason() = %q, %v, want %q, %v", reason, ok, test.wantReason, test.want)
+			}
+		})
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	tracks := []playlistTrack{
+		newPlaylistTrack(1, "id1", "ISRC1", "Numb", "Linkin Park"),
+		newPlaylistTrack(2, "id2", "ISRC2", "Faint", "Linkin Park"),
+		newPlaylistTrack(3, "id1", "ISRC1", "Numb", "Linkin Park"),
+		newPlaylistTrack(4, "id3", "ISRC2", "Faint", "Linkin Park"),
+		newPlaylistTrack(5, "id4", "ISRC4", "Numb (Live)", "Linkin Park"),
+		newPlaylistTrack(6, "id5", "ISRC5", "Numb", "U2"),
+		newPlaylistTrack(7, "", "", "Intro", ""),
+		newPlaylistTrack(8, "", "", "Intro", ""),
+	}
+
+	tests := []struct {
+		name           string
+		includeSimilar bool
+		want           []string
+	}{
+		{"exact duplicates", false, []string{"1 3", "2 4"}},
+		{"similar tracks", true, []string{"1 3 5", "2 4"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got []string
+
+			for _, cluster := range findDuplicates(tracks, test.includeSimilar) {
+				var positions []string
+
+				if cluster.reasons[0] != "" {
+					t.Errorf("the reason of the first track of the cluster is %q, want it empty", cluster.reasons[0])
+				}
+
+				for i, item := range cluster.tracks {
+					positions = append(positions, fmt.Sprint(item.position))
+
+					if i > 0 && cluster.reasons[i] == "" {
+						t.Errorf("the duplicate at position %d has no reason", item.position)
+					}
+				}
+
+				got = append(got, strings.Join(positions, " "))
+			}
+
+			if fmt.Sprint(got) != fmt.Sprint(test.want) {
+				t.Errorf("findDuplicates() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFindDuplicatesThroughAnotherTrack(t *testing.T) {
+	// The second track only shares the ISRC with the third one, which shares the ID with the first one
+	tracks := []playlistTrack{
+		newPlaylistTrack(1, "a", "ISRC1", "Numb", "Linkin Park"),
+		newPlaylistTrack(2, "b", "ISRC2", "Faint", "Linkin Park"),
+		newPlaylistTrack(3, "a", "ISRC2", "Numb", "Linkin Park"),
+	}
+	want := []string{"", utils.SameIsrcReason, utils.SameTrackReason}
+
+	for _, includeSimilar := range []bool{false, true} {
+		clusters := findDuplicates(tracks, includeSimilar)
+
+		if len(clusters) != 1 || len(clusters[0].tracks) != 3 {
+			t.Fatalf("findDuplicates(%v) = %v, want a cluster with the 3 tracks", includeSimilar, clusters)
+		}
+
+		if fmt.Sprintf("%q", clusters[0].reasons) != fmt.Sprintf("%q", want) {
+			t.Errorf("findDuplicates(%v) reasons = %q, want %q", includeSimilar, clusters[0].reasons, want)
+		}
+	}
+}
+
+func newTrackInfo(id string, isrc string, name string, artist string) trackInfo {
+	var info = trackInfo{Id: id, Name: name, ExternalIds: trackExternalIds{isrc}}
+
+	if id != "" {
+		info.Uri = "spotify:track:" + id
+	}
+
+	if artist != "" {
+		info.Artists = []trackArtist{{Name: artist}}
+	}
+
+	return info
+}
+
+func newPlaylistTrack(position int, id string, isrc string, name string, artist string) playlistTrack {
+	return playlistTrack{position: position, track: newTrackInfo(id, isrc, name, artist)}
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/CarlosGMI/Playlistify/utils"
+)
+
+type exportedTrack struct {
+	Position   int    `json:"position"`
+	Name       string `json:"name"`
+	Artists    string `json:"artists"`
+	Album      string `json:"album"`
+	DurationMs int    `json:"duration_ms"`
+	Isrc       string `json:"isrc"`
+	Uri        string `json:"uri"`
+	AddedAt    string `json:"added_at"`
+}
+
+type exportedPlaylist struct {
+	Id         string          `json:"id"`
+	Name       string          `json:"name"`
+	SnapshotId string          `json:"snapshot_id"`
+	Tracks     []exportedTrack `json:"tracks"`
+}
+
+type xspfTrack struct {
+	Location   string `xml:"location"`
+	Identifier string `xml:"identifier"`
+	Title      string `xml:"title"`
+	Creator    string `xml:"creator"`
+	Album      string `xml:"album,omitempty"`
+	Duration   int    `xml:"duration"`
+}
+
+type xspfPlaylist struct {
+	XMLName   xml.Name    `xml:"playlist"`
+	Version   string      `xml:"version,attr"`
+	Namespace string      `xml:"xmlns,attr"`
+	Title     string      `xml:"title"`
+	Location  string      `xml:"location"`
+	Tracks    []xspfTrack `xml:"trackList>track"`
+}
+
+var ExportFormats = []string{utils.ExportCSV, utils.ExportJSON, utils.ExportM3U8, utils.ExportXSPF}
+
+// ExportPlaylist writes every track of the playlist to the writer using one of the ExportFormats
+func ExportPlaylist(reference string, format string, writer io.Writer) error {
+	if !IsExportFormat(format) {
+		return fmt.Errorf(utils.InvalidExportFormatError, format)
+	}
+
+	playlist, tracks, err := resolvePlaylistTracks(reference)
+
+	if err != nil {
+		return err
+	}
+
+	exported := exportedPlaylist{
+		Id:         playlist.Id,
+		Name:       playlist.Name,
+		SnapshotId: playlist.SnapshotId,
+		Tracks:     []exportedTrack{},
+	}
+
+	for _, item := range tracks {
+		exported.Tracks = append(exported.Tracks, exportedTrack{
+			Position:   item.position,
+			Name:       item.track.Name,
+			Artists:    item.track.formattedArtists(),
+			Album:      item.track.Album.Name,
+			DurationMs: item.track.DurationMs,
+			Isrc:       item.track.ExternalIds.Isrc,
+			Uri:        item.track.Uri,
+			AddedAt:    item.addedAt,
+		})
+	}
+
+	switch format {
+	case utils.ExportCSV:
+		return exportCSV(&exported, writer)
+	case utils.ExportJSON:
+		return exportJSON(&exported, writer)
+	case utils.ExportM3U8:
+		return exportM3U8(&exported, writer)
+	default:
+		return exportXSPF(&exported, writer)
+	}
+}
+
+// ExportPlaylistToFile exports the playlist to a temporary file next to the file and renames it at the end, like
+// the journal, so a failed export never leaves the file truncated or half written
+func ExportPlaylistToFile(reference string, format string, fileName string) error {
+	var content bytes.Buffer
+
+	if err := ExportPlaylist(reference, format, &content); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(fileName+".tmp", content.Bytes(), 0644); err != nil {
+		return err
+	}
+
+	if err := os.Rename(fileName+".tmp", fileName); err != nil {
+		os.Remove(fileName + ".tmp")
+
+		return err
+	}
+
+	return nil
+}
+
+func IsExportFormat(format string) bool {
+	for _, exportFormat := range ExportFormats {
+		if format == exportFormat {
+			return true
+		}
+	}
+
+	return false
+}
+
+func exportCSV(playlist *exportedPlaylist, writer io.Writer) error {
+	csvWriter := csv.NewWriter(writer)
+	header := []string{"position", "name", "artists", "album", "duration_ms", "isrc", "uri", "added_at"}
+
+	if err := csvWriter.Write(header); err != nil {
+		return err
+	}
+
+	for _, track := range playlist.Tracks {
+		record := []string{
+			strconv.Itoa(track.Position),
+			track.Name,
+			track.Artists,
+			track.Album,
+			strconv.Itoa(track.DurationMs),
+			track.Isrc,
+			track.Uri,
+			track.AddedAt,
+		}
+
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+
+	return csvWriter.Error()
+}
+
+func exportJSON(playlist *exportedPlaylist, writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(playlist)
+}
+
+// exportM3U8 uses the Spotify URIs as the location of the tracks, since there are no files to point to
+func exportM3U8(playlist *exportedPlaylist, writer io.Writer) error {
+	var content strings.Builder
+
+	content.WriteString("#EXTM3U\n")
+	content.WriteString(fmt.Sprintf("#PLAYLIST:%s\n", getM3U8Value(playlist.Name)))
+
+	for _, track := range playlist.Tracks {
+		title := getM3U8Value(track.Artists + " - " + track.Name)
+
+		content.WriteString(fmt.Sprintf("#EXTINF:%d,%s\n", track.DurationMs/1000, title))
+		content.WriteString(track.Uri + "\n")
+	}
+
+	_, err := io.WriteString(writer, content.String())
+
+	return err
+}
+
+// getM3U8Value replaces the line breaks of a name, since every line of the file is a directive or a location
+func getM3U8Value(value string) string {
+	return strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ").Replace(value)
+}
+
+func exportXSPF(playlist *exportedPlaylist, writer io.Writer) error {
+	var xspf = xspfPlaylist{
+		Version:   "1",
+		Namespace: "http://xspf.org/ns/0/",
+		Title:     playlist.Name,
+		Location:  "spotify:playlist:" + playlist.Id,
+	}
+
+	for _, track := range playlist.Tracks {
+		xspf.Tracks = append(xspf.Tracks, xspfTrack{
+			Location:   track.Uri,
+			Identifier: track.Uri,
+			Title:      track.Name,
+			Creator:    track.Artists,
+			Album:      track.Album,
+			Duration:   track.DurationMs,
+		})
+	}
+
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+
+	if err := encoder.Encode(xspf); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(writer, "\n")
+
+	return err
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/CarlosGMI/Playlistify/services/fakeserver"
+	"github.com/CarlosGMI/Playlistify/utils"
+)
+
+func TestExportWriters(t *testing.T) {
+	playlist := &exportedPlaylist{
+		Id:         "37i9dQZF1DXcBWIGoYBM5M",
+		Name:       "Rock & Roll <Classics>",
+		SnapshotId: "s1",
+		Tracks: []exportedTrack{
+			{1, "Numb", "Linkin Park", "Meteora", 185586, "USWB10301050", "spotify:track:2nLtzopw4rPReszdYBJU6h", "2023-01-02T10:00:00Z"},
+			{2, `Back In Black "Live"`, "AC/DC, Guest", "Live, 1992", 254000, "", "spotify:local:AC%2FDC:Live:Back+In+Black:254", ""},
+		},
+	}
+
+	tests := []struct {
+		format string
+		export func(*exportedPlaylist, io.Writer) error
+		want   string
+	}{
+		{
+			format: utils.ExportCSV,
+			export: exportCSV,
+			want: `position,name,artists,album,duration_ms,isrc,uri,added_at
+1,Numb,Linkin Park,Meteora,185586,USWB10301050,spotify:track:2nLtzopw4rPReszdYBJU6h,2023-01-02T10:00:00Z
+2,"Back In Black ""Live""","AC/DC, Guest","Live, 1992",254000,,spotify:local:AC%2FDC:Live:Back+In+Black:254,
+`,
+		},
+		{
+			format: utils.ExportJSON,
+			export: exportJSON,
+			want: `{
+  "id": "37i9dQZF1DXcBWIGoYBM5M",
+  "name": "Rock \u0026 Roll \u003cClassics\u003e",
+  "snapshot_id": "s1",
+  "tracks": [
+    {
+      "position": 1,
+      "name": "Numb",
+      "artists": "Linkin Park",
+      "album": "Meteora",
+      "duration_ms": 185586,
+      "isrc": "USWB10301050",
+      "uri": "spotify:track:2nLtzopw4rPReszdYBJU6h",
+      "added_at": "2023-01-02T10:00:00Z"
+    },
+    {
+      "position": 2,
+      "name": "Back In Black \"Live\"",
+      "artists": "AC/DC, Guest",
+      "album": "Live, 1992",
+      "duration_ms": 254000,
+      "isrc": "",
+      "uri": "spotify:local:AC%2FDC:Live:Back+In+Black:254",
+      "added_at": ""
+    }
+  ]
+}
+`,
+		},
+		{
+			format: utils.ExportM3U8,
+			export: exportM3U8,
+			want: `#EXTM3U
+#PLAYLIST:Rock & Roll <Classics>
+#EXTINF:185,Linkin Park - Numb
+spotify:track:2nLtzopw4rPReszdYBJU6h
+#EXTINF:254,AC/DC, Guest - Back In Black "Live"
+spotify:local:AC%2FDC:Live:Back+In+Black:254
+`,
+		},
+		{
+			format: utils.ExportXSPF,
+			export: exportXSPF,
+			want: `<?xml version="1.0" encoding="UTF-8"?>
+<playlist version="1" xmlns="http://xspf.org/ns/0/">
+  <title>Rock &amp; Roll &lt;Classics&gt;</title>
+  <location>spotify:playlist:37i9dQZF1DXcBWIGoYBM5M</location>
+  <trackList>
+    <track>
+      <location>spotify:track:2nLtzopw4rPReszdYBJU6h</location>
+      <identifier>spotify:track:2nLtzopw4rPReszdYBJU6h</identifier>
+      <title>Numb</title>
+      <creator>Linkin Park</creator>
+      <album>Meteora</album>
+      <duration>185586</duration>
+    </track>
+    <track>
+      <location>spotify:local:AC%2FDC:Live:Back+In+Black:254</location>
+      <identifier>spotify:local:AC%2FDC:Live:Back+In+Black:254</identifier>
+      <title>Back In Black &#34;Live&#34;</title>
+      <creator>AC/DC, Guest</creator>
+      <album>Live, 1992</album>
+      <duration>254000</duration>
+    </track>
+  </trackList>
+</playlist>
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			var output bytes.Buffer
+
+			if err := test.export(playlist, &output); err != nil {
+				t.Fatalf("exporting as %s failed: %v", test.format, err)
+			}
+
+			if output.String() != test.want {
+				t.Errorf("the %s export is\n%s\nwant\n%s", test.format, output.String(), test.want)
+			}
+		})
+	}
+}
+
+func TestExportM3U8LineBreaks(t *testing.T) {
+	var output bytes.Buffer
+	var playlist = &exportedPlaylist{
+		Name:   "Road\nTrip",
+		Tracks: []exportedTrack{{Position: 1, Name: "Numb\r\n#EXTINF:0,Fake", Artists: "Linkin\rPark", DurationMs: 185586, Uri: "spotify:track:1"}},
+	}
+	want := "#EXTM3U\n#PLAYLIST:Road Trip\n#EXTINF:185,Linkin Park - Numb #EXTINF:0,Fake\nspotify:track:1\n"
+
+	if err := exportM3U8(playlist, &output); err != nil {
+		t.Fatalf("exportM3U8() error = %v", err)
+	}
+
+	if output.String() != want {
+		t.Errorf("exportM3U8() = %q, want %q", output.String(), want)
+	}
+}
+
+func TestExportPlaylistWithFakeServer(t *testing.T) {
+	var output bytes.Buffer
+
+	useFakeServer(t, fakeserver.Options{TokenTTL: 3600})
+
+	if err := ExportPlaylist("37i9dQZF1DXcBWIGoYBM5M", utils.ExportCSV, &output); err != nil {
+		t.Fatalf("ExportPlaylist() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+
+	if len(lines) < 2 || !strings.HasPrefix(lines[1], "1,Numb,Linkin Park,") {
+		t.Errorf("ExportPlaylist() = %q, want the header and the tracks of Road Trip", lines)
+	}
+
+	if err := ExportPlaylist("37i9dQZF1DXcBWIGoYBM5M", "pdf", &output); err == nil {
+		t.Error("ExportPlaylist() with an unknown format didn't fail")
+	}
+}
+
+func TestExportPlaylistToFileWithFakeServer(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "road-trip.csv")
+
+	useFakeServer(t, fakeserver.Options{TokenTTL: 3600})
+
+	if err := os.WriteFile(fileName, []byte("previous export"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ExportPlaylistToFile("spotify:playlist:0000000000000000000000", utils.ExportCSV, fileName); err == nil {
+		t.Fatal("ExportPlaylistToFile() of an unknown playlist didn't fail")
+	}
+
+	if content, err := os.ReadFile(fileName); err != nil || string(content) != "previous export" {
+		t.Errorf("the file after a failed export is %q, %v, want it unchanged", content, err)
+	}
+
+	if err := ExportPlaylistToFile("37i9dQZF1DXcBWIGoYBM5M", utils.ExportCSV, fileName); err != nil {
+		t.Fatalf("ExportPlaylistToFile() error = %v", err)
+	}
+
+	if content, err := os.ReadFile(fileName); err != nil || !strings.HasPrefix(string(content), "position,name,") {
+		t.Errorf("the exported file is %q, %v, want the CSV export", content, err)
+	}
+
+	if _, err := os.Stat(fileName + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("the temporary file was left behind: %v", err)
+	}
+}
@@ -0,0 +1,702 @@
+// Package fakeserver implements a small stand-in of the Spotify Web API and Accounts service, serving the
+// playlists of a fixture directory. It's meant for offline development and end to end tests of Playlistify
+package fakeserver
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//go:embed fixtures
+var defaultFixtures embed.FS
+
+const defaultPageLimit = 20
+const maxPageLimit = 50
+const maxModifiedTracks = 100
+
+type Options struct {
+	// Fixtures is the directory with the me.json, playlists.json, tracks/{playlist id}.json and the optional
+	// catalog.json (tracks that aren't in any playlist) files. The embedded fixtures are used when it's empty
+	Fixtures string
+	Port     int
+	// RateLimitEvery answers every nth API request with a 429, 0 disables it
+	RateLimitEvery int
+	RetryAfter     int
+	// TokenTTL is the number of seconds the access tokens are valid
+	TokenTTL int
+}
+
+type fixtureOwner struct {
+	Id string `json:"id"`
+}
+
+type fixturePlaylist struct {
+	Id            string       `json:"id"`
+	Name          string       `json:"name"`
+	Collaborative bool         `json:"collaborative"`
+	Public        bool         `json:"public"`
+	Owner         fixtureOwner `json:"owner"`
+	SnapshotId    string       `json:"snapshot_id"`
+}
+
+type fixtureTrack struct {
+	AddedAt string          `json:"added_at"`
+	Track   json.RawMessage `json:"track"`
+}
+
+type fakePlaylist struct {
+	fixturePlaylist
+	tracks []fixtureTrack
+}
+
+type server struct {
+	options   Options
+	mutex     sync.Mutex
+	user      json.RawMessage
+	playlists []*fakePlaylist
+	// catalog has every track of the fixtures by URI, they are the tracks that can be added to the playlists
+	catalog       map[string]json.RawMessage
+	catalogUris   []string
+	accessTokens  map[string]time.Time
+	refreshTokens map[string]bool
+	codes         map[string]bool
+	requests      int
+}
+
+// Run loads the fixtures and serves the fake API until the process is stopped
+func Run(options Options) error {
+	server, err := newServer(options)
+
+	if err != nil {
+		return err
+	}
+
+	address := fmt.Sprintf("localhost:%d", options.Port)
+
+	fmt.Printf("Fake Spotify server listening on http://%s with %d playlists\n\n", address, len(server.playlists))
+	fmt.Println("Point Playlistify to it with:")
+	fmt.Printf("  export PLAYLISTIFY_API_BASE_URL=http://%s/v1\n", address)
+	fmt.Printf("  export PLAYLISTIFY_ACCOUNTS_BASE_URL=http://%s\n", address)
+
+	return http.ListenAndServe(address, server.routes())
+}
+
+// NewHandler loads the fixtures and returns the handler of the fake API, so the tests can serve it with httptest
+func NewHandler(options Options) (http.Handler, error) {
+	server, err := newServer(options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return server.routes(), nil
+}
+
+func newServer(options Options) (*server, error) {
+	var fixtures fs.FS
+	var playlists []fixturePlaylist
+	var catalog []json.RawMessage
+	var server = &server{
+		options:       options,
+		accessTokens:  map[string]time.Time{},
+		refreshTokens: map[string]bool{},
+		codes:         map[string]bool{},
+		catalog:       map[string]json.RawMessage{},
+	}
+
+	if options.Fixtures == "" {
+		fixtures, _ = fs.Sub(defaultFixtures, "fixtures")
+	} else {
+		fixtures = os.DirFS(options.Fixtures)
+	}
+
+	user, err := fs.ReadFile(fixtures, "me.json")
+
+	if err != nil {
+		return nil, err
+	}
+
+	server.user = user
+
+	if err := readFixture(fixtures, "playlists.json", &playlists); err != nil {
+		return nil, err
+	}
+
+	for _, playlist := range playlists {
+		var fake = &fakePlaylist{fixturePlaylist: playlist}
+
+		if err := readFixture(fixtures, path.Join("tracks", playlist.Id+".json"), &fake.tracks); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+
+		for _, track := range fake.tracks {
+			server.addToCatalog(track.Track)
+		}
+
+		server.playlists = append(server.playlists, fake)
+	}
+
+	if err := readFixture(fixtures, "catalog.json", &catalog); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	for _, track := range catalog {
+		server.addToCatalog(track)
+	}
+
+	return server, nil
+}
+
+func (server *server) addToCatalog(track json.RawMessage) {
+	uri := getTrackUri(fixtureTrack{Track: track})
+
+	if _, ok := server.catalog[uri]; !ok {
+		server.catalog[uri] = track
+		server.catalogUris = append(server.catalogUris, uri)
+	}
+}
+
+func readFixture(fixtures fs.FS, name string, value interface{}) error {
+	content, err := fs.ReadFile(fixtures, name)
+
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(content, value); err != nil {
+		return fmt.Errorf("invalid fixture %s: %w", name, err)
+	}
+
+	return nil
+}
+
+func (server *server) routes() *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/authorize", server.handleAuthorize)
+	mux.HandleFunc("/api/token", server.handleToken)
+	mux.HandleFunc("/v1/", server.handleAPI)
+
+	return mux
+}
+
+// handleAuthorize skips the login and consent pages, redirecting right away to the callback with a new code
+func (server *server) handleAuthorize(writer http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+
+	if err != nil || query.Get("redirect_uri") == "" {
+		http.Error(writer, "invalid redirect_uri", http.StatusBadRequest)
+
+		return
+	}
+
+	server.mutex.Lock()
+	code := generateToken()
+	server.codes[code] = true
+	server.mutex.Unlock()
+
+	callbackQuery := redirectURI.Query()
+	callbackQuery.Set("code", code)
+	callbackQuery.Set("state", query.Get("state"))
+	redirectURI.RawQuery = callbackQuery.Encode()
+
+	http.Redirect(writer, request, redirectURI.String(), http.StatusFound)
+}
+
+func (server *server) handleToken(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		writeTokenError(writer, http.StatusMethodNotAllowed, "invalid_request", "only POST is allowed")
+
+		return
+	}
+
+	if err := request.ParseForm(); err != nil {
+		writeTokenError(writer, http.StatusBadRequest, "invalid_request", err.Error())
+
+		return
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	switch request.PostForm.Get("grant_type") {
+	case "authorization_code":
+		code := request.PostForm.Get("code")
+
+		if !server.codes[code] {
+			writeTokenError(writer, http.StatusBadRequest, "invalid_grant", "Invalid authorization code")
+
+			return
+		}
+
+		delete(server.codes, code)
+	case "refresh_token":
+		refreshToken := request.PostForm.Get("refresh_token")
+
+		if !server.refreshTokens[refreshToken] {
+			writeTokenError(writer, http.StatusBadRequest, "invalid_grant", "Invalid refresh token")
+
+			return
+		}
+
+		// The refresh tokens are rotated like Spotify does for the PKCE flow
+		delete(server.refreshTokens, refreshToken)
+	default:
+		writeTokenError(writer, http.StatusBadRequest, "unsupported_grant_type", "grant_type must be authorization_code or refresh_token")
+
+		return
+	}
+
+	accessToken := generateToken()
+	refreshToken := generateToken()
+	server.accessTokens[accessToken] = time.Now().Add(time.Duration(server.options.TokenTTL) * time.Second)
+	server.refreshTokens[refreshToken] = true
+
+	writeJSON(writer, http.StatusOK, map[string]interface{}{
+		"access_token":  accessToken,
+		"token_type":    "Bearer",
+		"scope":         request.PostForm.Get("scope"),
+		"expires_in":    server.options.TokenTTL,
+		"refresh_token": refreshToken,
+	})
+}
+
+func (server *server) handleAPI(writer http.ResponseWriter, request *http.Request) {
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(request.URL.Path, "/v1"), "/"), "/")
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	if !server.isAuthorized(request) {
+		writeAPIError(writer, http.StatusUnauthorized, "The access token expired")
+
+		return
+	}
+
+	server.requests++
+
+	if server.options.RateLimitEvery > 0 && server.requests%server.options.RateLimitEvery == 0 {
+		writer.Header().Set("Retry-After", strconv.Itoa(server.options.RetryAfter))
+		writeAPIError(writer, http.StatusTooManyRequests, "API rate limit exceeded")
+
+		return
+	}
+
+	switch {
+	case len(segments) == 1 && segments[0] == "me" && request.Method == http.MethodGet:
+		writer.Header().Set("Content-Type", "application/json")
+		writer.Write(server.user)
+	case len(segments) == 2 && segments[0] == "me" && segments[1] == "playlists" && request.Method == http.MethodGet:
+		server.getPlaylists(writer, request)
+	case len(segments) == 1 && segments[0] == "search" && request.Method == http.MethodGet:
+		server.searchTracks(writer, request)
+	case len(segments) == 1 && segments[0] == "tracks" && request.Method == http.MethodGet:
+		server.getTracks(writer, request)
+	case len(segments) == 3 && segments[0] == "users" && segments[2] == "playlists" && request.Method == http.MethodPost:
+		server.createPlaylist(writer, request, segments[1])
+	case len(segments) == 2 && segments[0] == "playlists" && request.Method == http.MethodGet:
+		server.getPlaylist(writer, request, segments[1])
+	case len(segments) == 3 && segments[0] == "playlists" && segments[2] == "tracks" && request.Method == http.MethodGet:
+		server.getPlaylistTracks(writer, request, segments[1])
+	case len(segments) == 3 && segments[0] == "playlists" && segments[2] == "tracks" && request.Method == http.MethodPost:
+		server.addPlaylistTracks(writer, request, segments[1])
+	case len(segments) == 3 && segments[0] == "playlists" && segments[2] == "tracks" && request.Method == http.MethodDelete:
+		server.removePlaylistTracks(writer, request, segments[1])
+	default:
+		writeAPIError(writer, http.StatusNotFound, "Service not found")
+	}
+}
+
+func (server *server) isAuthorized(request *http.Request) bool {
+	accessToken := strings.TrimPrefix(request.Header.Get("Authorization"), "Bearer ")
+	expiration, ok := server.accessTokens[accessToken]
+
+	return ok && time.Now().Before(expiration)
+}
+
+func (server *server) getPlaylists(writer http.ResponseWriter, request *http.Request) {
+	var items []interface{}
+
+	for _, playlist := range server.playlists {
+		items = append(items, server.formatPlaylist(request, playlist))
+	}
+
+	writeJSON(writer, http.StatusOK, paginate(request, items))
+}
+
+func (server *server) getPlaylist(writer http.ResponseWriter, request *http.Request, id string) {
+	playlist := server.findPlaylist(id)
+
+	if playlist == nil {
+		writeAPIError(writer, http.StatusNotFound, "Resource not found")
+
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, server.formatPlaylist(request, playlist))
+}
+
+func (server *server) getPlaylistTracks(writer http.ResponseWriter, request *http.Request, id string) {
+	var items []interface{}
+	playlist := server.findPlaylist(id)
+
+	if playlist == nil {
+		writeAPIError(writer, http.StatusNotFound, "Resource not found")
+
+		return
+	}
+
+	for _, track := range playlist.tracks {
+		items = append(items, track)
+	}
+
+	writeJSON(writer, http.StatusOK, paginate(request, items))
+}
+
+// searchTracks returns the tracks of the catalog whose name and artists contain every word of the query. The
+// track: and artist: field filters are accepted but not applied to a single field
+func (server *server) searchTracks(writer http.ResponseWriter, request *http.Request) {
+	var items []interface{}
+	query := strings.ToLower(request.URL.Query().Get("q"))
+	words := strings.Fields(strings.NewReplacer("track:", " ", "artist:", " ").Replace(query))
+
+	if len(words) == 0 {
+		writeAPIError(writer, http.StatusBadRequest, "No search query")
+
+		return
+	}
+
+	for _, uri := range server.catalogUris {
+		var info struct {
+			Name    string `json:"name"`
+			Artists []struct {
+				Name string `json:"name"`
+			} `json:"artists"`
+		}
+		var matches = true
+
+		json.Unmarshal(server.catalog[uri], &info)
+		text := strings.ToLower(info.Name)
+
+		for _, artist := range info.Artists {
+			text += " " + strings.ToLower(artist.Name)
+		}
+
+		for _, word := range words {
+			matches = matches && strings.Contains(text, word)
+		}
+
+		if matches {
+			items = append(items, server.catalog[uri])
+		}
+	}
+
+	writeJSON(writer, http.StatusOK, map[string]interface{}{"tracks": paginate(request, items)})
+}
+
+// getTracks returns the tracks of the ids parameter, with null for the ones that aren't in the catalog
+func (server *server) getTracks(writer http.ResponseWriter, request *http.Request) {
+	var tracks []interface{}
+
+	for _, id := range strings.Split(request.URL.Query().Get("ids"), ",") {
+		if track, ok := server.catalog["spotify:track:"+id]; ok {
+			tracks = append(tracks, track)
+		} else {
+			tracks = append(tracks, nil)
+		}
+	}
+
+	writeJSON(writer, http.StatusOK, map[string]interface{}{"tracks": tracks})
+}
+
+// createPlaylist creates an empty playlist, only for the fixture user
+func (server *server) createPlaylist(writer http.ResponseWriter, request *http.Request, userId string) {
+	var user fixtureOwner
+	var body struct {
+		Name   string `json:"name"`
+		Public bool   `json:"public"`
+	}
+
+	json.Unmarshal(server.user, &user)
+
+	if userId != user.Id {
+		writeAPIError(writer, http.StatusForbidden, "You cannot create a playlist for another user")
+
+		return
+	}
+
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil || body.Name == "" {
+		writeAPIError(writer, http.StatusBadRequest, "Missing required field: name")
+
+		return
+	}
+
+	playlist := &fakePlaylist{fixturePlaylist: fixturePlaylist{
+		Id:         generateToken()[:22],
+		Name:       body.Name,
+		Public:     body.Public,
+		Owner:      user,
+		SnapshotId: generateToken()[:16],
+	}}
+	server.playlists = append(server.playlists, playlist)
+
+	writeJSON(writer, http.StatusCreated, server.formatPlaylist(request, playlist))
+}
+
+// addPlaylistTracks inserts the tracks of the catalog at the position, or at the end when there's no position
+func (server *server) addPlaylistTracks(writer http.ResponseWriter, request *http.Request, id string) {
+	var body struct {
+		Uris     []string `json:"uris"`
+		Position *int     `json:"position"`
+	}
+	var newTracks []fixtureTrack
+	playlist := server.findPlaylist(id)
+
+	if playlist == nil {
+		writeAPIError(writer, http.StatusNotFound, "Resource not found")
+
+		return
+	}
+
+	if !server.canModify(playlist) {
+		writeAPIError(writer, http.StatusForbidden, "You cannot modify this playlist")
+
+		return
+	}
+
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil || len(body.Uris) == 0 || len(body.Uris) > maxModifiedTracks {
+		writeAPIError(writer, http.StatusBadRequest, "Invalid request body")
+
+		return
+	}
+
+	position := len(playlist.tracks)
+
+	if body.Position != nil {
+		position = *body.Position
+	}
+
+	if position < 0 || position > len(playlist.tracks) {
+		writeAPIError(writer, http.StatusBadRequest, "Index out of bounds")
+
+		return
+	}
+
+	for _, uri := range body.Uris {
+		track, ok := server.catalog[uri]
+
+		if !ok {
+			writeAPIError(writer, http.StatusBadRequest, "Invalid track uri: "+uri)
+
+			return
+		}
+
+		newTracks = append(newTracks, fixtureTrack{time.Now().UTC().Format(time.RFC3339), track})
+	}
+
+	tracks := append([]fixtureTrack(nil), playlist.tracks[:position]...)
+	tracks = append(tracks, newTracks...)
+	playlist.tracks = append(tracks, playlist.tracks[position:]...)
+	playlist.SnapshotId = generateToken()[:16]
+
+	writeJSON(writer, http.StatusCreated, map[string]string{"snapshot_id": playlist.SnapshotId})
+}
+
+// removePlaylistTracks removes the occurrences at the given positions, which must belong to the current snapshot
+// of the playlist and point to the given URIs
+func (server *server) removePlaylistTracks(writer http.ResponseWriter, request *http.Request, id string) {
+	var body struct {
+		Tracks []struct {
+			Uri       string `json:"uri"`
+			Positions []int  `json:"positions"`
+		} `json:"tracks"`
+		SnapshotId string `json:"snapshot_id"`
+	}
+	var removedPositions = map[int]bool{}
+	var remainingTracks []fixtureTrack
+	playlist := server.findPlaylist(id)
+
+	if playlist == nil {
+		writeAPIError(writer, http.StatusNotFound, "Resource not found")
+
+		return
+	}
+
+	if !server.canModify(playlist) {
+		writeAPIError(writer, http.StatusForbidden, "You cannot modify this playlist")
+
+		return
+	}
+
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil || len(body.Tracks) > maxModifiedTracks {
+		writeAPIError(writer, http.StatusBadRequest, "Invalid request body")
+
+		return
+	}
+
+	if body.SnapshotId != "" && body.SnapshotId != playlist.SnapshotId {
+		writeAPIError(writer, http.StatusBadRequest, "Invalid snapshot id")
+
+		return
+	}
+
+	for _, item := range body.Tracks {
+		for _, position := range item.Positions {
+			if position < 0 || position >= len(playlist.tracks) || getTrackUri(playlist.tracks[position]) != item.Uri {
+				writeAPIError(writer, http.StatusBadRequest, "Could not remove tracks, please check parameters.")
+
+				return
+			}
+
+			removedPositions[position] = true
+		}
+	}
+
+	for position, track := range playlist.tracks {
+		if !removedPositions[position] {
+			remainingTracks = append(remainingTracks, track)
+		}
+	}
+
+	playlist.tracks = remainingTracks
+	playlist.SnapshotId = generateToken()[:16]
+
+	writeJSON(writer, http.StatusOK, map[string]string{"snapshot_id": playlist.SnapshotId})
+}
+
+// canModify tells if the fixture user owns the playlist or it's collaborative
+func (server *server) canModify(playlist *fakePlaylist) bool {
+	var user fixtureOwner
+
+	json.Unmarshal(server.user, &user)
+
+	return playlist.Owner.Id == user.Id || playlist.Collaborative
+}
+
+func getTrackUri(track fixtureTrack) string {
+	var info struct {
+		Uri string `json:"uri"`
+	}
+
+	json.Unmarshal(track.Track, &info)
+
+	return info.Uri
+}
+
+func (server *server) findPlaylist(id string) *fakePlaylist {
+	for _, playlist := range server.playlists {
+		if playlist.Id == id {
+			return playlist
+		}
+	}
+
+	return nil
+}
+
+func (server *server) formatPlaylist(request *http.Request, playlist *fakePlaylist) map[string]interface{} {
+	return map[string]interface{}{
+		"id":            playlist.Id,
+		"name":          playlist.Name,
+		"type":          "playlist",
+		"uri":           "spotify:playlist:" + playlist.Id,
+		"collaborative": playlist.Collaborative,
+		"public":        playlist.Public,
+		"owner":         playlist.Owner,
+		"snapshot_id":   playlist.SnapshotId,
+		"tracks": map[string]interface{}{
+			"total": len(playlist.tracks),
+			"href":  fmt.Sprintf("http://%s/v1/playlists/%s/tracks", request.Host, playlist.Id),
+		},
+	}
+}
+
+// paginate returns the page of items requested with the limit and offset parameters, with a next link like the
+// Spotify paging objects
+func paginate(request *http.Request, items []interface{}) map[string]interface{} {
+	var next interface{}
+	query := request.URL.Query()
+	limit := getIntParameter(query, "limit", defaultPageLimit)
+	offset := getIntParameter(query, "offset", 0)
+
+	if limit < 1 || limit > maxPageLimit {
+		limit = defaultPageLimit
+	}
+
+	if offset < 0 || offset > len(items) {
+		offset = len(items)
+	}
+
+	end := offset + limit
+
+	if end >= len(items) {
+		end = len(items)
+	} else {
+		query.Set("offset", strconv.Itoa(end))
+		query.Set("limit", strconv.Itoa(limit))
+		next = fmt.Sprintf("http://%s%s?%s", request.Host, request.URL.Path, query.Encode())
+	}
+
+	page := items[offset:end]
+
+	if page == nil {
+		page = []interface{}{}
+	}
+
+	return map[string]interface{}{
+		"href":   fmt.Sprintf("http://%s%s", request.Host, request.URL.RequestURI()),
+		"items":  page,
+		"limit":  limit,
+		"offset": offset,
+		"total":  len(items),
+		"next":   next,
+	}
+}
+
+func getIntParameter(query url.Values, name string, defaultValue int) int {
+	value, err := strconv.Atoi(query.Get(name))
+
+	if err != nil {
+		return defaultValue
+	}
+
+	return value
+}
+
+func generateToken() string {
+	const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	token := make([]byte, 40)
+
+	for i := range token {
+		token[i] = letters[rand.Intn(len(letters))]
+	}
+
+	return string(token)
+}
+
+func writeJSON(writer http.ResponseWriter, status int, value interface{}) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	json.NewEncoder(writer).Encode(value)
+}
+
+func writeAPIError(writer http.ResponseWriter, status int, message string) {
+	writeJSON(writer, status, map[string]interface{}{
+		"error": map[string]interface{}{"status": status, "message": message},
+	})
+}
+
+func writeTokenError(writer http.ResponseWriter, status int, code string, description string) {
+	writeJSON(writer, status, map[string]string{"error": code, "error_description": description})
+}
@@ -14,7 +14,7 @@ const commonPrefixLimiter = 4
 
 // To avoid panicing later on, order strings according to
 // their unicode length.
-func sort(s1, s2 string) (shorter, longer string) {
+func orderByLength(s1, s2 string) (shorter, longer string) {
 	if utf8.RuneCountInString(s1) < utf8.RuneCountInString(s2) {
 		return s1, s2
 	}
@@ -72,7 +72,7 @@ func CalculateJaroWinkler(s1, s2 string) float64 {
 		return 0
 	}
 
-	s1, s2 = sort(strings.ToLower(s1), strings.ToLower(s2))
+	s1, s2 = orderByLength(strings.ToLower(s1), strings.ToLower(s2))
 
 	// m as `matching characters`
 	// t as `transposition`
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/CarlosGMI/Playlistify/utils"
+)
+
+// journalTrack is a track removed from or added to a playlist, with its (1-based) position at that moment
+type journalTrack struct {
+	Uri      string `json:"uri"`
+	Position int    `json:"position"`
+	Name     string `json:"name"`
+	Artists  string `json:"artists"`
+}
+
+// journalEntry records a change made to a playlist, with everything needed to revert it. The removed tracks have
+// their positions before the change and the added ones their positions after it
+type journalEntry struct {
+	Id             string         `json:"id"`
+	Operation      string         `json:"operation"`
+	CreatedAt      time.Time      `json:"created_at"`
+	PlaylistId     string         `json:"playlist_id"`
+	PlaylistName   string         `json:"playlist_name"`
+	SnapshotBefore string         `json:"snapshot_before"`
+	SnapshotAfter  string         `json:"snapshot_after"`
+	Removed        []journalTrack `json:"removed,omitempty"`
+	Added          []journalTrack `json:"added,omitempty"`
+	// Undoes is the operation reverted by an undo, and UndoneBy the undo that reverted the operation
+	Undoes   string `json:"undoes,omitempty"`
+	UndoneBy string `json:"undone_by,omitempty"`
+}
+
+// recordOperation appends the entry to the journal, setting its ID and creation date
+func recordOperation(entry *journalEntry) error {
+	entries, err := readJournal()
+
+	if err != nil {
+		return err
+	}
+
+	entry.CreatedAt = time.Now()
+	entry.Id = entry.CreatedAt.Format("20060102-150405")
+
+	for suffix := 2; findJournalEntry(entries, entry.Id) != nil; suffix++ {
+		entry.Id = fmt.Sprintf("%s-%d", entry.CreatedAt.Format("20060102-150405"), suffix)
+	}
+
+	return writeJournal(append(entries, *entry))
+}
+
+// markAsUndone links the operation with the undo that reverted it
+func markAsUndone(id string, undoId string) error {
+	entries, err := readJournal()
+
+	if err != nil {
+		return err
+	}
+
+	entry := findJournalEntry(entries, id)
+
+	if entry == nil {
+		return fmt.Errorf(utils.UnknownOperationError, id)
+	}
+
+	entry.UndoneBy = undoId
+
+	return writeJournal(entries)
+}
+
+// GetHistory lists the operations of the journal, starting with the most recent one
+func GetHistory() (TableResultsMsg, error) {
+	var message = TableResultsMsg{TableType: utils.HistoryTable}
+	entries, err := readJournal()
+
+	if err != nil {
+		return message, err
+	}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		operation := entry.Operation
+
+		if entry.Undoes != "" {
+			operation = fmt.Sprintf("%s %s", entry.Operation, entry.Undoes)
+		}
+
+		message.appendRow([]string{
+			entry.Id,
+			entry.CreatedAt.Local().Format("2006-01-02 15:04:05"),
+			operation,
+			entry.PlaylistName,
+			strconv.Itoa(len(entry.Removed)),
+			strconv.Itoa(len(entry.Added)),
+			entry.UndoneBy,
+		})
+	}
+
+	message.Title = fmt.Sprintf(utils.HistoryTitle, len(entries))
+
+	return message, nil
+}
+
+func findJournalEntry(entries []journalEntry, id string) *journalEntry {
+	for i := range entries {
+		if entries[i].Id == id {
+			return &entries[i]
+		}
+	}
+
+	return nil
+}
+
+func getJournalFile() (string, error) {
+	directory, err := os.UserConfigDir()
+
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(utils.ProfileDirectory(filepath.Join(directory, "playlistify"), utils.ActiveProfile()), utils.JournalFile), nil
+}
+
+func readJournal() ([]journalEntry, error) {
+	var entries []journalEntry
+	fileName, err := getJournalFile()
+
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(fileName)
+
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func writeJournal(entries []journalEntry) error {
+	fileName, err := getJournalFile()
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fileName), 0700); err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(entries, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	// Like the cache, the journal is renamed at the end so it's never left half written
+	if err := os.WriteFile(fileName+".tmp", content, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(fileName+".tmp", fileName)
+}
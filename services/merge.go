@@ -0,0 +1,215 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/CarlosGMI/Playlistify/utils"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/viper"
+)
+
+// mergeTrack is a track of one of the playlists of a merge
+type mergeTrack struct {
+	playlistName string
+	item         playlistTrack
+}
+
+// mergeIndex finds the duplicates of a track among the tracks of the merge, comparing the names only with the
+// tracks of the same main artist like findDuplicates
+type mergeIndex struct {
+	includeSimilar bool
+	byId           map[string]mergeTrack
+	byIsrc         map[string]mergeTrack
+	byArtist       map[string][]mergeTrack
+}
+
+// mergeTarget is the playlist that receives the tracks, which is created when the merge is confirmed if it's new
+type mergeTarget struct {
+	playlist *playlist
+	name     string
+	tracks   int
+}
+
+// MergePlaylists adds the tracks of the source playlists, in order, to the target playlist (a new one when
+// newTarget is enabled). The tracks that are already in the target or in a previous source, by ID, ISRC or (when
+// includeSimilar is enabled) a similar name and artist, are skipped. The changes are returned as a ConfirmationMsg
+func MergePlaylists(sources []string, into string, newTarget bool, includeSimilar bool) tea.Msg {
+	var message = TableResultsMsg{TableType: utils.MergeTable}
+	var target = mergeTarget{name: into}
+	var index = mergeIndex{includeSimilar, map[string]mergeTrack{}, map[string]mergeTrack{}, map[string][]mergeTrack{}}
+	var tracksToAdd []mergeTrack
+	var skipped int
+
+	if !newTarget {
+		playlist, tracks, err := resolvePlaylistTracks(into)
+
+		if err != nil {
+			return errorToMsg(err)
+		}
+
+		if !isOwnPlaylist(playlist, viper.GetString(utils.ProfileKey("user_id"))) {
+			return PlaylistsErrorMsg{fmt.Sprintf(utils.NotEditablePlaylistError, playlist.Name)}
+		}
+
+		target = mergeTarget{playlist, playlist.Name, len(tracks)}
+
+		for _, item := range tracks {
+			index.add(mergeTrack{playlist.Name, item})
+		}
+	}
+
+	for _, source := range sources {
+		playlist, tracks, err := resolvePlaylistTracks(source)
+
+		if err != nil {
+			return errorToMsg(err)
+		}
+
+		if target.playlist != nil && playlist.Id == target.playlist.Id {
+			return PlaylistsErrorMsg{fmt.Sprintf(utils.MergeIntoSourceError, playlist.Name)}
+		}
+
+		for _, item := range tracks {
+			track := mergeTrack{playlist.Name, item}
+			row := []string{utils.AddAction, playlist.Name, strconv.Itoa(item.position), item.track.Name, item.track.formattedArtists(), ""}
+
+			if reason := index.findDuplicate(item.track); reason != "" {
+				row[0], row[5] = utils.SkipAction, reason
+				skipped++
+			} else if strings.HasPrefix(item.track.Uri, "spotify:local:") || item.track.Uri == "" {
+				row[0], row[5] = utils.SkipAction, utils.LocalFileReason
+				skipped++
+			} else {
+				tracksToAdd = append(tracksToAdd, track)
+				index.add(track)
+			}
+
+			message.appendRow(row)
+		}
+	}
+
+	if len(tracksToAdd) == 0 {
+		message.Title = fmt.Sprintf(utils.NothingToMergeTitle, target.name, skipped)
+
+		return message
+	}
+
+	message.Title = fmt.Sprintf(utils.MergeConfirmationTitle, len(tracksToAdd), target.name, len(sources), skipped)
+
+	if newTarget {
+		message.Title = fmt.Sprintf(utils.MergeNewConfirmationTitle, target.name, len(tracksToAdd), len(sources), skipped)
+	}
+
+	return ConfirmationMsg{
+		TableResultsMsg: message,
+		ProgressText:    "Merging the playlists...",
+		Action: func() tea.Msg {
+			return mergeTracks(target, tracksToAdd, message)
+		},
+	}
+}
+
+// mergeTracks appends the tracks to the target and records it in the journal. The results keep the skipped tracks
+// of the summary
+func mergeTracks(target mergeTarget, tracksToAdd []mergeTrack, summary TableResultsMsg) tea.Msg {
+	var message = TableResultsMsg{TableType: utils.MergeTable}
+	var uris []string
+
+	if target.playlist == nil {
+		target.playlist = new(playlist)
+
+		if err := createPlaylist(target.name, viper.GetString(utils.ProfileKey("user_id")), target.playlist); err != nil {
+			return PlaylistsErrorMsg{err.Error()}
+		}
+	}
+
+	entry := journalEntry{
+		Operation:      utils.MergeOperation,
+		PlaylistId:     target.playlist.Id,
+		PlaylistName:   target.playlist.Name,
+		SnapshotBefore: target.playlist.SnapshotId,
+	}
+
+	for _, track := range tracksToAdd {
+		uris = append(uris, track.item.track.Uri)
+	}
+
+	added, err := addTracks(target.playlist, uris, target.tracks+1)
+
+	for i, track := range tracksToAdd[:added] {
+		entry.Added = append(entry.Added, journalTrack{track.item.track.Uri, target.tracks + i + 1, track.item.track.Name, track.item.track.formattedArtists()})
+	}
+
+	if added > 0 {
+		entry.SnapshotAfter = target.playlist.SnapshotId
+
+		if journalErr := recordOperation(&entry); journalErr != nil {
+			return PlaylistsErrorMsg{fmt.Sprintf(utils.AddJournalError, added, target.playlist.Name, journalErr.Error())}
+		}
+	}
+
+	if err != nil && added == 0 {
+		return PlaylistsErrorMsg{err.Error()}
+	}
+
+	if err != nil {
+		return PlaylistsErrorMsg{fmt.Sprintf(utils.PartialAddError, err.Error(), added, len(tracksToAdd), entry.Id)}
+	}
+
+	for _, row := range summary.Results {
+		resultRow := append([]string(nil), row...)
+
+		if resultRow[0] == utils.AddAction {
+			resultRow[0] = utils.AddedAction
+		}
+
+		message.appendRow(resultRow)
+	}
+
+	message.Title = fmt.Sprintf(utils.MergeTitle, added, target.playlist.Name, len(summary.Results)-added, entry.Id)
+
+	return message
+}
+
+func (index *mergeIndex) add(track mergeTrack) {
+	info := track.item.track
+
+	if _, ok := index.byId[info.Id]; !ok && info.Id != "" {
+		index.byId[info.Id] = track
+	}
+
+	if _, ok := index.byIsrc[info.ExternalIds.Isrc]; !ok && info.ExternalIds.Isrc != "" {
+		index.byIsrc[info.ExternalIds.Isrc] = track
+	}
+
+	index.byArtist[info.mainArtist()] = append(index.byArtist[info.mainArtist()], track)
+}
+
+// findDuplicate explains why the track is a duplicate of one of the indexed tracks, or returns an empty string
+func (index *mergeIndex) findDuplicate(info trackInfo) string {
+	if track, ok := index.byId[info.Id]; ok && info.Id != "" {
+		return track.describeDuplicate(utils.SameTrackReason)
+	}
+
+	if track, ok := index.byIsrc[info.ExternalIds.Isrc]; ok && info.ExternalIds.Isrc != "" {
+		return track.describeDuplicate(utils.SameIsrcReason)
+	}
+
+	if !index.includeSimilar {
+		return ""
+	}
+
+	for _, track := range index.byArtist[info.mainArtist()] {
+		if reason, isDuplicate := getDuplicateReason(track.item.track, info, index.includeSimilar); isDuplicate {
+			return track.describeDuplicate(reason)
+		}
+	}
+
+	return ""
+}
+
+func (track mergeTrack) describeDuplicate(reason string) string {
+	return fmt.Sprintf(utils.MergeDuplicateNote, track.playlistName, track.item.position, track.item.track.Name, reason)
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/CarlosGMI/Playlistify/services/fakeserver"
+	"github.com/CarlosGMI/Playlistify/utils"
+)
+
+func TestMergePlaylistsWithFakeServer(t *testing.T) {
+	useFakeServer(t, fakeserver.Options{TokenTTL: 3600})
+
+	confirmation, ok := MergePlaylists([]string{"Chill Evenings"}, roadTrip2Id, false, false).(ConfirmationMsg)
+
+	if !ok {
+		t.Fatalf("MergePlaylists() = %#v, want a confirmation", confirmation)
+	}
+
+	var added []string
+
+	for _, row := range confirmation.Results {
+		// Two Hearts is the first track of Chill Evenings and it's already in Road Trip 2
+		if row[3] == "Two Hearts" && row[0] != utils.SkipAction {
+			t.Errorf("the track %v of the target wasn't skipped", row)
+		}
+
+		if row[0] == utils.AddAction {
+			added = append(added, row[3])
+		}
+	}
+
+	if len(added) == 0 || len(added) == len(confirmation.Results) {
+		t.Fatalf("MergePlaylists() adds %d of %d tracks, want some of them skipped", len(added), len(confirmation.Results))
+	}
+
+	if msg, ok := confirmation.Action().(TableResultsMsg); !ok {
+		t.Fatalf("the merge action = %#v", msg)
+	}
+
+	_, tracks, err := resolvePlaylistTracks(roadTrip2Id)
+
+	if err != nil {
+		t.Fatalf("resolvePlaylistTracks() error = %v", err)
+	}
+
+	var appended []string
+
+	for _, item := range tracks[15:] {
+		appended = append(appended, item.track.Name)
+	}
+
+	if fmt.Sprint(appended) != fmt.Sprint(added) {
+		t.Errorf("the tracks appended to the target are %v, want %v", appended, added)
+	}
+
+	journal, err := readJournal()
+
+	if err != nil || len(journal) != 1 || journal[0].Operation != utils.MergeOperation || len(journal[0].Added) != len(added) {
+		t.Errorf("the journal is %#v, %v, want the merged tracks", journal, err)
+	}
+}
+
+func TestMergePlaylistsTargetsWithFakeServer(t *testing.T) {
+	useFakeServer(t, fakeserver.Options{TokenTTL: 3600})
+
+	tests := []struct {
+		name      string
+		sources   []string
+		into      string
+		newTarget bool
+		want      string
+	}{
+		{"new target", []string{roadTrip2Id, roadTrip2Id}, "Mix", true, "15 add, 15 skip"},
+		{"every track in the target", []string{roadTrip2Id}, "37i9dQZF1DXcBWIGoYBM5M", false, "15 skip"},
+		{"target is a source", []string{roadTrip2Id}, roadTrip2Id, false, fmt.Sprintf(utils.MergeIntoSourceError, "Road Trip 2")},
+		{"target of another user", []string{roadTrip2Id}, "0vvXsWCC9xrXsKd4FyS8kM", false, fmt.Sprintf(utils.NotEditablePlaylistError, "Followed Hits")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var results TableResultsMsg
+
+			switch msg := MergePlaylists(test.sources, test.into, test.newTarget, false).(type) {
+			case ConfirmationMsg:
+				results = msg.TableResultsMsg
+			case TableResultsMsg:
+				results = msg
+			case PlaylistsErrorMsg:
+				if msg.Message != test.want {
+					t.Errorf("MergePlaylists() error = %q, want %q", msg.Message, test.want)
+				}
+
+				return
+			default:
+				t.Fatalf("MergePlaylists() = %#v", msg)
+			}
+
+			if got := countRowActions(results); got != test.want {
+				t.Errorf("MergePlaylists() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+// countRowActions summarizes the actions of the rows, like "10 add, 2 skip"
+func countRowActions(msg TableResultsMsg) string {
+	var actions []string
+	var counts = map[string]int{}
+
+	for _, row := range msg.Results {
+		if counts[row[0]] == 0 {
+			actions = append(actions, row[0])
+		}
+
+		counts[row[0]]++
+	}
+
+	for i, action := range actions {
+		actions[i] = fmt.Sprintf("%d %s", counts[action], action)
+	}
+
+	return strings.Join(actions, ", ")
+}
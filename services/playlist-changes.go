@@ -0,0 +1,158 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/CarlosGMI/Playlistify/utils"
+)
+
+type trackPositions struct {
+	Uri       string `json:"uri"`
+	Positions []int  `json:"positions"`
+}
+
+type removeTracksBody struct {
+	Tracks     []trackPositions `json:"tracks"`
+	SnapshotId string           `json:"snapshot_id"`
+}
+
+type addTracksBody struct {
+	Uris []string `json:"uris"`
+	// Position is the (0-based) position of the first track, they are appended when it's nil
+	Position *int `json:"position,omitempty"`
+}
+
+type snapshotResponse struct {
+	SnapshotId string `json:"snapshot_id"`
+}
+
+// removeTracks removes the occurrences of the tracks at their positions and returns the ones that were removed,
+// which are only part of them when a batch fails. The positions are removed from the highest to the lowest one, so
+// the batches sent later aren't affected by the tracks removed before them. The snapshot of the playlist is
+// updated after every batch
+func removeTracks(playlist *playlist, tracks []playlistTrack) ([]playlistTrack, error) {
+	var url = fmt.Sprintf("%s/playlists/%s/tracks", utils.APIBaseURL(), playlist.Id)
+	sortedTracks := append([]playlistTrack(nil), tracks...)
+
+	if playlist.SnapshotId == "" {
+		return nil, fmt.Errorf(utils.MissingSnapshotError, playlist.Name)
+	}
+
+	sort.Slice(sortedTracks, func(i, j int) bool {
+		return sortedTracks[i].position > sortedTracks[j].position
+	})
+
+	for start := 0; start < len(sortedTracks); start += utils.ModifyTracksLimit {
+		var response = new(snapshotResponse)
+		end := start + utils.ModifyTracksLimit
+
+		if end > len(sortedTracks) {
+			end = len(sortedTracks)
+		}
+
+		body := removeTracksBody{Tracks: groupPositionsByUri(sortedTracks[start:end]), SnapshotId: playlist.SnapshotId}
+		payload, err := json.Marshal(body)
+
+		if err != nil {
+			return sortedTracks[:start], err
+		}
+
+		if err := MakeRequest(http.MethodDelete, url, bytes.NewReader(payload), response); err != nil {
+			return sortedTracks[:start], modifyPlaylistError(playlist, err)
+		}
+
+		utils.Debugf("removed %d tracks from %s, snapshot %s", end-start, playlist.Id, response.SnapshotId)
+
+		if response.SnapshotId != "" {
+			playlist.SnapshotId = response.SnapshotId
+		}
+	}
+
+	return sortedTracks, nil
+}
+
+// addTracks inserts the tracks at the (1-based) position, or appends them when the position is 0, in batches of
+// up to utils.ModifyTracksLimit tracks. It returns the number of tracks that were added, which are only part of
+// them when a batch fails
+func addTracks(playlist *playlist, uris []string, position int) (int, error) {
+	var url = fmt.Sprintf("%s/playlists/%s/tracks", utils.APIBaseURL(), playlist.Id)
+
+	for start := 0; start < len(uris); start += utils.ModifyTracksLimit {
+		var response = new(snapshotResponse)
+		var body = addTracksBody{}
+		end := start + utils.ModifyTracksLimit
+
+		if end > len(uris) {
+			end = len(uris)
+		}
+
+		body.Uris = uris[start:end]
+
+		if position > 0 {
+			batchPosition := position - 1 + start
+			body.Position = &batchPosition
+		}
+
+		payload, err := json.Marshal(body)
+
+		if err != nil {
+			return start, err
+		}
+
+		if err := MakeRequest(http.MethodPost, url, bytes.NewReader(payload), response); err != nil {
+			return start, modifyPlaylistError(playlist, err)
+		}
+
+		utils.Debugf("added %d tracks to %s, snapshot %s", end-start, playlist.Id, response.SnapshotId)
+
+		if response.SnapshotId != "" {
+			playlist.SnapshotId = response.SnapshotId
+		}
+	}
+
+	return len(uris), nil
+}
+
+// groupPositionsByUri converts the (1-based) positions of the tracks to the (0-based) positions of the API,
+// grouping the occurrences of the same track
+func groupPositionsByUri(tracks []playlistTrack) []trackPositions {
+	var groups []trackPositions
+	var indexes = map[string]int{}
+
+	for _, item := range tracks {
+		index, ok := indexes[item.track.Uri]
+
+		if !ok {
+			index = len(groups)
+			indexes[item.track.Uri] = index
+			groups = append(groups, trackPositions{Uri: item.track.Uri})
+		}
+
+		groups[index].Positions = append(groups[index].Positions, item.position-1)
+	}
+
+	return groups
+}
+
+func modifyPlaylistError(playlist *playlist, err error) error {
+	if isForbiddenError(err) {
+		return errors.New(utils.MissingModifyScopeError)
+	}
+
+	return fmt.Errorf(utils.ModifyPlaylistError, playlist.Name, err.Error())
+}
+
+func journalTracks(tracks []playlistTrack) []journalTrack {
+	var entries []journalTrack
+
+	for _, item := range tracks {
+		entries = append(entries, journalTrack{item.track.Uri, item.position, item.track.Name, item.track.formattedArtists()})
+	}
+
+	return entries
+}
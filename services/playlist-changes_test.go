@@ -0,0 +1,47 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestGroupPositionsByUri(t *testing.T) {
+	tests := []struct {
+		name   string
+		tracks []playlistTrack
+		want   []trackPositions
+	}{
+		{
+			name:   "no tracks",
+			tracks: nil,
+			want:   nil,
+		},
+		{
+			name: "different tracks",
+			tracks: []playlistTrack{
+				newPlaylistTrack(1, "id1", "", "Numb", "Linkin Park"),
+				newPlaylistTrack(5, "id2", "", "Faint", "Linkin Park"),
+			},
+			want: []trackPositions{{"spotify:track:id1", []int{0}}, {"spotify:track:id2", []int{4}}},
+		},
+		{
+			name: "occurrences of the same track",
+			tracks: []playlistTrack{
+				newPlaylistTrack(9, "id1", "", "Numb", "Linkin Park"),
+				newPlaylistTrack(7, "id2", "", "Faint", "Linkin Park"),
+				newPlaylistTrack(3, "id1", "", "Numb", "Linkin Park"),
+			},
+			want: []trackPositions{{"spotify:track:id1", []int{8, 2}}, {"spotify:track:id2", []int{6}}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := groupPositionsByUri(test.tracks)
+
+			if fmt.Sprint(got) != fmt.Sprint(test.want) {
+				t.Errorf("groupPositionsByUri() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
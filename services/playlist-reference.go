@@ -0,0 +1,260 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/CarlosGMI/Playlistify/utils"
+	"github.com/charmbracelet/bubbles/table"
+	textTable "github.com/jedib0t/go-pretty/v6/table"
+	"github.com/lithammer/fuzzysearch/fuzzy"
+	"github.com/spf13/viper"
+)
+
+type playlistCandidate struct {
+	index    int
+	playlist playlist
+	score    float64
+}
+
+// AmbiguousPlaylistError is returned when a playlist name matches several playlists with a similar score
+type AmbiguousPlaylistError struct {
+	Reference  string
+	candidates []playlistCandidate
+}
+
+// AmbiguousPlaylistMsg lets the user pick one of the playlists that matched the name
+type AmbiguousPlaylistMsg struct {
+	Message  string
+	Results  []table.Row
+	TextRows []textTable.Row
+}
+
+func (err *AmbiguousPlaylistError) Error() string {
+	var names []string
+
+	for _, candidate := range err.candidates {
+		names = append(names, fmt.Sprintf("%q (%d)", candidate.playlist.Name, candidate.index))
+	}
+
+	return fmt.Sprintf(utils.AmbiguousPlaylistError, err.Reference, strings.Join(names, ", "))
+}
+
+func (err *AmbiguousPlaylistError) toMsg() AmbiguousPlaylistMsg {
+	var message = AmbiguousPlaylistMsg{Message: err.Error()}
+
+	for _, candidate := range err.candidates {
+		index := strconv.Itoa(candidate.index)
+		total := strconv.Itoa(candidate.playlist.Tracks.Total)
+		message.Results = append(message.Results, table.Row{index, candidate.playlist.Name, total})
+		message.TextRows = append(message.TextRows, textTable.Row{index, candidate.playlist.Name, total})
+	}
+
+	return message
+}
+
+const playlistFields = "id,name,collaborative,type,owner(id),tracks(total,href),snapshot_id"
+
+// playlistNameThreshold is the minimum Jaro-Winkler score for a playlist name to be considered a match and
+// playlistAmbiguityMargin the score difference under which two matching names are considered equally good
+const playlistNameThreshold = 0.8
+const playlistAmbiguityMargin = 0.05
+
+var spotifyIdPattern = regexp.MustCompile(`^[0-9A-Za-z]{22}$`)
+
+// resolvePlaylist finds the playlist referenced by the --playlist flag. The reference can be the index shown by the
+// list command, a Spotify playlist ID, a spotify:playlist: URI, an open.spotify.com/playlist URL or the name of
+// one of the cached playlists
+func resolvePlaylist(reference string) (*playlist, error) {
+	var apiError *requestError
+	reference = strings.TrimSpace(reference)
+
+	if index, err := strconv.Atoi(reference); err == nil {
+		playlist, err := getPlaylistByIndex(index)
+
+		if err != nil {
+			return getPlaylistByNameOr(reference, err)
+		}
+
+		return playlist, nil
+	}
+
+	if id, ok := parsePlaylistId(reference); ok {
+		playlist, err := getPlaylistById(id)
+
+		// Only a bare ID can be a name, the URIs and URLs always reference an ID
+		if id == reference && errors.As(err, &apiError) && apiError.Status == http.StatusNotFound {
+			return getPlaylistByNameOr(reference, err)
+		}
+
+		return playlist, err
+	}
+
+	return getPlaylistByName(reference)
+}
+
+// getPlaylistByNameOr looks for a playlist named like the reference when there isn't a playlist with the index or
+// ID it looks like, such as "2024". When no name matches either the lookup error is returned
+func getPlaylistByNameOr(reference string, lookupErr error) (*playlist, error) {
+	var ambiguousError *AmbiguousPlaylistError
+	playlist, err := getPlaylistByName(reference)
+
+	if err != nil && !errors.As(err, &ambiguousError) {
+		return nil, lookupErr
+	}
+
+	return playlist, err
+}
+
+func parsePlaylistId(reference string) (string, bool) {
+	return parseSpotifyId(reference, "playlist")
+}
+
+// parseSpotifyId gets the ID of a spotify:{type}: URI, an open.spotify.com/{type} URL or the ID itself
+func parseSpotifyId(reference string, itemType string) (string, bool) {
+	if uriPrefix := "spotify:" + itemType + ":"; strings.HasPrefix(reference, uriPrefix) {
+		reference = strings.TrimPrefix(reference, uriPrefix)
+	} else if parsedURL, err := url.Parse(reference); err == nil && parsedURL.Host == "open.spotify.com" {
+		// The path can contain a locale or the user before the item, for example /intl-es/playlist/{id}
+		segments := strings.Split(strings.Trim(parsedURL.Path, "/"), "/")
+
+		if len(segments) < 2 || segments[len(segments)-2] != itemType {
+			return "", false
+		}
+
+		reference = segments[len(segments)-1]
+	}
+
+	return reference, spotifyIdPattern.MatchString(reference)
+}
+
+func getPlaylistByIndex(index int) (*playlist, error) {
+	var playlists []playlist
+	var playlist = new(playlist)
+
+	if err := viper.UnmarshalKey(utils.ProfileKey("playlists"), &playlists); err != nil {
+		return nil, err
+	}
+
+	if index >= 0 && index < len(playlists) {
+		return &playlists[index], nil
+	}
+
+	if err := getPlaylistWithOffset(strconv.Itoa(index), playlist); err != nil {
+		return nil, err
+	}
+
+	return playlist, nil
+}
+
+// getPlaylistByName looks for the playlist using the same fuzzy and Jaro-Winkler scoring used to search tracks. When
+// several playlists match with a similar score an AmbiguousPlaylistError is returned
+func getPlaylistByName(name string) (*playlist, error) {
+	var candidates []playlistCandidate
+	term := strings.ToLower(name)
+	playlists, err := getCachedPlaylists()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for index, playlist := range playlists {
+		playlistName := strings.ToLower(playlist.Name)
+
+		if playlistName == term {
+			return &playlists[index], nil
+		}
+
+		nameScore := CalculateJaroWinkler(term, playlistName)
+
+		if fuzzy.Match(term, playlistName) || nameScore > playlistNameThreshold {
+			candidates = append(candidates, playlistCandidate{index, playlist, nameScore})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf(utils.InvalidPlaylistReferenceError, name)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+
+	closeCandidates := candidates[:1]
+
+	for _, candidate := range candidates[1:] {
+		if candidates[0].score-candidate.score < playlistAmbiguityMargin {
+			closeCandidates = append(closeCandidates, candidate)
+		}
+	}
+
+	if len(closeCandidates) > 1 {
+		return nil, &AmbiguousPlaylistError{name, closeCandidates}
+	}
+
+	return &candidates[0].playlist, nil
+}
+
+// getCachedPlaylists returns the playlists stored by the list command, fetching them if they were never stored
+func getCachedPlaylists() ([]playlist, error) {
+	var playlists []playlist
+
+	if err := viper.UnmarshalKey(utils.ProfileKey("playlists"), &playlists); err != nil {
+		return nil, err
+	}
+
+	if len(playlists) > 0 {
+		return playlists, nil
+	}
+
+	if msg, ok := GetPlaylists().(PlaylistsErrorMsg); ok {
+		return nil, errors.New(msg.Message)
+	}
+
+	err := viper.UnmarshalKey(utils.ProfileKey("playlists"), &playlists)
+
+	return playlists, err
+}
+
+func getPlaylistById(id string) (*playlist, error) {
+	var playlist = new(playlist)
+	var query = url.Values{
+		"fields": {playlistFields},
+	}
+	var url = fmt.Sprintf("%s/playlists/%s?%s", utils.APIBaseURL(), id, query.Encode())
+
+	if err := MakeRequest(http.MethodGet, url, nil, playlist); err != nil {
+		return nil, err
+	}
+
+	playlist.snapshotChecked = true
+
+	return playlist, nil
+}
+
+func getPlaylistWithOffset(id string, playlist *playlist) error {
+	var playlistsResults = new(Playlists)
+	var query = url.Values{
+		"limit":  {"1"},
+		"offset": {id},
+	}
+	var url = fmt.Sprintf("%s/me/playlists?%s", utils.APIBaseURL(), query.Encode())
+
+	if err := MakeRequest(http.MethodGet, url, nil, playlistsResults); err != nil {
+		return err
+	}
+
+	if len(playlistsResults.Items) == 0 {
+		return fmt.Errorf(utils.InexistentPlaylistError, id)
+	}
+
+	*playlist = playlistsResults.Items[0]
+
+	return nil
+}
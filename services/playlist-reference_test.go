@@ -0,0 +1,148 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/CarlosGMI/Playlistify/services/fakeserver"
+	"github.com/CarlosGMI/Playlistify/utils"
+	"github.com/spf13/viper"
+)
+
+func TestParseSpotifyId(t *testing.T) {
+	const id = "37i9dQZF1DXcBWIGoYBM5M"
+
+	tests := []struct {
+		name      string
+		reference string
+		itemType  string
+		want      string
+		wantOk    bool
+	}{
+		{"id", id, "playlist", id, true},
+		{"uri", "spotify:playlist:" + id, "playlist", id, true},
+		{"uri of another type", "spotify:track:" + id, "playlist", "spotify:track:" + id, false},
+		{"url", "https://open.spotify.com/playlist/" + id, "playlist", id, true},
+		{"url with query", "https://open.spotify.com/playlist/" + id + "?si=abc123", "playlist", id, true},
+		{"url with locale", "https://open.spotify.com/intl-es/playlist/" + id, "playlist", id, true},
+		{"url of another type", "https://open.spotify.com/album/" + id, "playlist", "", false},
+		{"url without id", "https://open.spotify.com/playlist", "playlist", "", false},
+		{"url of another host", "https://example.com/playlist/" + id, "playlist", "https://example.com/playlist/" + id, false},
+		{"track url", "https://open.spotify.com/track/" + id, "track", id, true},
+		{"short id", "37i9dQZF1DXcBWIGoYBM5", "playlist", "37i9dQZF1DXcBWIGoYBM5", false},
+		{"name", "Road Trip", "playlist", "Road Trip", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := parseSpotifyId(test.reference, test.itemType)
+
+			if got != test.want || ok != test.wantOk {
+				t.Errorf("parseSpotifyId(%q, %q) = %q, %v, want %q, %v", test.reference, test.itemType, got, ok, test.want, test.wantOk)
+			}
+		})
+	}
+}
+
+func TestGetPlaylistByName(t *testing.T) {
+	setCachedPlaylists(t, "Road Trip", "Road Trip 2019", "Road Trip 2020", "Chill Vibes", "Workout Mix")
+
+	tests := []struct {
+		name          string
+		reference     string
+		want          string
+		wantAmbiguous []string
+		wantErr       bool
+	}{
+		{"exact name", "Road Trip", "Road Trip", nil, false},
+		{"exact name in another case", "chill vibes", "Chill Vibes", nil, false},
+		{"single fuzzy match", "workout", "Workout Mix", nil, false},
+		{"similar name", "Chill Vibez", "Chill Vibes", nil, false},
+		{"ambiguous name", "road trip 20", "", []string{"Road Trip 2019", "Road Trip 2020", "Road Trip"}, false},
+		{"unknown name", "Metal", "", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var ambiguousError *AmbiguousPlaylistError
+			got, err := getPlaylistByName(test.reference)
+
+			switch {
+			case test.wantAmbiguous != nil:
+				if !errors.As(err, &ambiguousError) {
+					t.Fatalf("getPlaylistByName(%q) error = %v, want an AmbiguousPlaylistError", test.reference, err)
+				}
+
+				var names []string
+
+				for _, candidate := range ambiguousError.candidates {
+					names = append(names, candidate.playlist.Name)
+				}
+
+				if fmt.Sprint(names) != fmt.Sprint(test.wantAmbiguous) {
+					t.Errorf("getPlaylistByName(%q) candidates = %v, want %v", test.reference, names, test.wantAmbiguous)
+				}
+			case test.wantErr:
+				if err == nil || errors.As(err, &ambiguousError) {
+					t.Errorf("getPlaylistByName(%q) error = %v, want a not found error", test.reference, err)
+				}
+			case err != nil:
+				t.Fatalf("getPlaylistByName(%q) error = %v", test.reference, err)
+			case got.Name != test.want:
+				t.Errorf("getPlaylistByName(%q) = %q, want %q", test.reference, got.Name, test.want)
+			}
+		})
+	}
+}
+
+func TestResolvePlaylistWithFakeServer(t *testing.T) {
+	useFakeServer(t, fakeserver.Options{TokenTTL: 3600})
+	setCachedPlaylists(t, "Road Trip", "2024", "RoadTripSummerMix2024A")
+
+	tests := []struct {
+		name      string
+		reference string
+		want      string
+		wantErr   bool
+	}{
+		{"index", "1", "2024", false},
+		{"numeric name", "2024", "2024", false},
+		{"id", "37i9dQZF1DXcBWIGoYBM5M", "Road Trip", false},
+		{"name that looks like an id", "RoadTripSummerMix2024A", "RoadTripSummerMix2024A", false},
+		{"uri of an unknown id", "spotify:playlist:RoadTripSummerMix2024A", "", true},
+		{"unknown index", "99", "", true},
+		{"unknown id", "AbCdEfGhIjKlMnOpQrStUv", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := resolvePlaylist(test.reference)
+
+			switch {
+			case test.wantErr:
+				if err == nil {
+					t.Errorf("resolvePlaylist(%q) = %q, want an error", test.reference, got.Name)
+				}
+			case err != nil:
+				t.Fatalf("resolvePlaylist(%q) error = %v", test.reference, err)
+			case got.Name != test.want:
+				t.Errorf("resolvePlaylist(%q) = %q, want %q", test.reference, got.Name, test.want)
+			}
+		})
+	}
+}
+
+// setCachedPlaylists stores the playlists like the list command does, so they can be found without requests
+func setCachedPlaylists(t *testing.T, names ...string) {
+	var playlists []playlist
+
+	for i, name := range names {
+		playlists = append(playlists, playlist{Id: fmt.Sprintf("playlist%d", i), Name: name, Tracks: playlistTracksInfo{Total: 10}})
+	}
+
+	viper.Set(utils.ProfileKey("playlists"), playlists)
+	t.Cleanup(func() {
+		viper.Set(utils.ProfileKey("playlists"), nil)
+	})
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"net/http"
@@ -34,6 +35,9 @@ type playlist struct {
 	Type          string             `json:"type"`
 	Owner         playlistOwner      `json:"owner"`
 	Tracks        playlistTracksInfo `json:"tracks"`
+	SnapshotId    string             `json:"snapshot_id"`
+	// snapshotChecked tells if the snapshot was fetched during this run, the cached playlists may be outdated
+	snapshotChecked bool
 }
 
 type Playlists struct {
@@ -46,20 +50,60 @@ type trackArtist struct {
 	Name string `json:"name"`
 }
 
+type trackExternalIds struct {
+	Isrc string `json:"isrc"`
+}
+
+type trackAlbum struct {
+	Name string `json:"name"`
+}
+
 type trackInfo struct {
-	Id      string        `json:"id"`
-	Name    string        `json:"name"`
-	Artists []trackArtist `json:"artists"`
+	Id          string           `json:"id"`
+	Uri         string           `json:"uri"`
+	Name        string           `json:"name"`
+	DurationMs  int              `json:"duration_ms"`
+	Album       trackAlbum       `json:"album"`
+	Artists     []trackArtist    `json:"artists"`
+	ExternalIds trackExternalIds `json:"external_ids"`
 }
 
 type track struct {
-	Track trackInfo `json:"track"`
+	AddedAt string    `json:"added_at"`
+	Track   trackInfo `json:"track"`
+}
+
+// playlistTrack is a track together with its (1-based) position in the playlist
+type playlistTrack struct {
+	position int
+	addedAt  string
+	track    trackInfo
 }
 
 type playlistTracks struct {
 	Tracks []track `json:"items"`
 }
 
+type trackMatch struct {
+	position int
+	name     string
+	artists  string
+	uri      string
+}
+
+type searchResults struct {
+	status      string
+	matches     []trackMatch
+	failedPages []int
+	err         error
+}
+
+type tracksPage struct {
+	number int
+	tracks []track
+	err    error
+}
+
 type PlaylistsErrorMsg struct {
 	Message string
 }
@@ -69,6 +113,43 @@ type SearchResultsMsg struct {
 	PlaylistName string
 	Results      []table.Row
 	TextResults  []textTable.Row
+	// Status is one of utils.SearchComplete, utils.SearchPartial or utils.SearchFailed
+	Status string
+	// FailedPages contains the (1-based) pages of tracks that couldn't be fetched
+	FailedPages []int
+	// FailedPlaylists contains the playlists with pages that couldn't be fetched when searching in all of them
+	FailedPlaylists []string
+	// matches are the matched tracks, in the same order as the results
+	matches []trackMatch
+	Error   string
+}
+
+// TableResultsMsg contains the results of the commands that only show a table, like dupes
+type TableResultsMsg struct {
+	TableType   string
+	Title       string
+	Results     []table.Row
+	TextResults []textTable.Row
+}
+
+// ConfirmationMsg shows the changes a command is about to make, which are only made by its Action once the user
+// confirms them
+type ConfirmationMsg struct {
+	TableResultsMsg
+	Question     string
+	ProgressText string
+	Action       tea.Cmd
+}
+
+func (msg *TableResultsMsg) appendRow(row []string) {
+	var textRow textTable.Row
+
+	for _, value := range row {
+		textRow = append(textRow, value)
+	}
+
+	msg.Results = append(msg.Results, table.Row(row))
+	msg.TextResults = append(msg.TextResults, textRow)
 }
 
 func GetPlaylists() tea.Msg {
@@ -76,7 +157,7 @@ func GetPlaylists() tea.Msg {
 	var query = url.Values{
 		"limit": {strconv.Itoa(utils.TracksLimit)},
 	}
-	var url = fmt.Sprintf("%s/me/playlists?%s", utils.SpotifyAPIBaseURL, query.Encode())
+	var url = fmt.Sprintf("%s/me/playlists?%s", utils.APIBaseURL(), query.Encode())
 
 	if err := fetchPlaylists(&playlists, url); err != nil {
 		return PlaylistsErrorMsg{err.Error()}
@@ -106,7 +187,7 @@ func fetchPlaylists(playlists *[]playlist, url string) error {
 }
 
 func storePlaylists(playlists *[]playlist) {
-	viper.Set("playlists", playlists)
+	viper.Set(utils.ProfileKey("playlists"), playlists)
 	viper.WriteConfig()
 }
 
@@ -114,14 +195,14 @@ func PrintPlaylists() ([]table.Row, []textTable.Row, error) {
 	var playlists []playlist
 	var rows []table.Row
 	var textRows []textTable.Row
-	userId := viper.GetString("user_id")
+	userId := viper.GetString(utils.ProfileKey("user_id"))
 
-	if err := viper.UnmarshalKey("playlists", &playlists); err != nil {
+	if err := viper.UnmarshalKey(utils.ProfileKey("playlists"), &playlists); err != nil {
 		return rows, textRows, err
 	}
 
 	for index, playlist := range playlists {
-		if playlist.Owner.Id == userId || playlist.Collaborative {
+		if isOwnPlaylist(&playlist, userId) {
 			rows = append(rows, table.Row{strconv.Itoa(index), playlist.Name, strconv.Itoa(playlist.Tracks.Total)})
 			textRows = append(textRows, textTable.Row{strconv.Itoa(index), playlist.Name, strconv.Itoa(playlist.Tracks.Total)})
 		}
@@ -130,89 +211,233 @@ func PrintPlaylists() ([]table.Row, []textTable.Row, error) {
 	return rows, textRows, nil
 }
 
+// isOwnPlaylist tells if the playlist belongs to the user or is collaborative, which are the playlists listed
+func isOwnPlaylist(playlist *playlist, userId string) bool {
+	return playlist.Owner.Id == userId || playlist.Collaborative
+}
+
 func SearchInPlaylist(playlistId string, searchTerm string) tea.Msg {
-	var playlists []playlist
-	var playlist = new(playlist)
-	formattedId, err := strconv.Atoi(playlistId)
+	playlist, err := resolvePlaylist(playlistId)
 
 	if err != nil {
-		return PlaylistsErrorMsg{err.Error()}
+		return errorToMsg(err)
 	}
 
-	if err := viper.UnmarshalKey("playlists", &playlists); err != nil {
+	search := getTracksAndSearch(playlist, strings.ToLower(searchTerm))
+
+	return createSearchResultsMsg(playlist.Name, search)
+}
+
+// SearchInAllPlaylists looks for the term in every playlist listed by PrintPlaylists
+func SearchInAllPlaylists(searchTerm string) tea.Msg {
+	var message = SearchResultsMsg{PlaylistName: utils.AllPlaylistsName, Status: utils.SearchComplete}
+	var searchedPlaylists, failedPlaylists int
+	userId := viper.GetString(utils.ProfileKey("user_id"))
+	playlists, err := getCachedPlaylists()
+
+	if err != nil {
 		return PlaylistsErrorMsg{err.Error()}
 	}
 
-	if len(playlists) > 0 && formattedId <= len(playlists) {
-		playlist = &playlists[formattedId]
-	} else {
-		if err := getPlaylistWithOffset(strconv.Itoa(formattedId), playlist); err != nil {
-			return PlaylistsErrorMsg{err.Error()}
+	for i := range playlists {
+		if !isOwnPlaylist(&playlists[i], userId) || playlists[i].Tracks.Total == 0 {
+			continue
 		}
+
+		search := getTracksAndSearch(&playlists[i], strings.ToLower(searchTerm))
+		searchedPlaylists++
+
+		if search.err != nil {
+			message.FailedPlaylists = append(message.FailedPlaylists, playlists[i].Name)
+			message.Error = search.err.Error()
+		}
+
+		if search.status == utils.SearchFailed {
+			failedPlaylists++
+		}
+
+		for _, match := range search.matches {
+			position := strconv.Itoa(match.position)
+			message.Results = append(message.Results, table.Row{playlists[i].Name, position, match.name, match.artists})
+			message.TextResults = append(message.TextResults, textTable.Row{playlists[i].Name, position, match.name, match.artists})
+			message.matches = append(message.matches, match)
+		}
+	}
+
+	if failedPlaylists == searchedPlaylists && searchedPlaylists > 0 {
+		message.Status = utils.SearchFailed
+	} else if len(message.FailedPlaylists) > 0 {
+		message.Status = utils.SearchPartial
 	}
 
-	results, textResults, _ := getTracksAndSearch(playlist, strings.ToLower(searchTerm))
+	return message
+}
+
+// Warning explains which tracks are missing from partial results
+func (msg SearchResultsMsg) Warning() string {
+	if len(msg.FailedPlaylists) > 0 {
+		return fmt.Sprintf(utils.PartialAllSearchWarning, strings.Join(msg.FailedPlaylists, ", "))
+	}
 
-	return SearchResultsMsg{playlist.Name, results, textResults}
+	return fmt.Sprintf(utils.PartialSearchWarning, utils.FormatNumbers(msg.FailedPages))
 }
 
-func getPlaylistWithOffset(id string, playlist *playlist) error {
-	var playlistsResults = new(Playlists)
-	var query = url.Values{
-		"limit":  {"1"},
-		"offset": {id},
+// errorToMsg lets the TUI ask the user to pick a playlist when the name was ambiguous
+func errorToMsg(err error) tea.Msg {
+	var ambiguousError *AmbiguousPlaylistError
+
+	if errors.As(err, &ambiguousError) {
+		return ambiguousError.toMsg()
 	}
-	var url = fmt.Sprintf("%s/me/playlists?%s", utils.SpotifyAPIBaseURL, query.Encode())
 
-	if err := MakeRequest(http.MethodGet, url, nil, playlistsResults); err != nil {
-		return err
+	return PlaylistsErrorMsg{err.Error()}
+}
+
+func createSearchResultsMsg(playlistName string, search searchResults) SearchResultsMsg {
+	var message = SearchResultsMsg{
+		PlaylistName: playlistName,
+		Status:       search.status,
+		FailedPages:  search.failedPages,
 	}
 
-	if len(playlistsResults.Items) == 0 {
-		return fmt.Errorf(utils.InexistentPlaylistError, id)
+	if search.err != nil {
+		message.Error = search.err.Error()
 	}
 
-	*playlist = playlistsResults.Items[0]
+	for _, match := range search.matches {
+		message.Results = append(message.Results, table.Row{strconv.Itoa(match.position), match.name, match.artists})
+		message.TextResults = append(message.TextResults, textTable.Row{strconv.Itoa(match.position), match.name, match.artists})
+		message.matches = append(message.matches, match)
+	}
 
-	return nil
+	return message
 }
 
-func getTracksAndSearch(playlist *playlist, searchTerm string) ([]table.Row, []textTable.Row, error) {
-	var results []table.Row
-	var textResults []textTable.Row
-	waitGroup := sync.WaitGroup{}
-	numberOfRequests := math.Ceil(float64(playlist.Tracks.Total) / utils.TracksLimit)
+func getTracksAndSearch(playlist *playlist, searchTerm string) searchResults {
+	var search = searchResults{status: utils.SearchComplete}
+	pages := fetchAllTracks(playlist)
+
+	for _, page := range pages {
+		if page.err != nil {
+			search.failedPages = append(search.failedPages, page.number+1)
+			search.err = page.err
+
+			continue
+		}
+
+		// The pages come in order, so appending their matches keeps the results sorted by position
+		search.matches = append(search.matches, executeSearch(page.tracks, searchTerm, page.number)...)
+	}
+
+	if len(search.failedPages) == len(pages) && len(pages) > 0 {
+		search.status = utils.SearchFailed
+	} else if len(search.failedPages) > 0 {
+		search.status = utils.SearchPartial
+	}
+
+	return search
+}
+
+// fetchAllTracks returns all the pages of tracks of a playlist, in order. They are taken from the cache while the
+// snapshot of the playlist doesn't change
+func fetchAllTracks(playlist *playlist) []tracksPage {
+	refreshPlaylistSnapshot(playlist)
+
+	if pages, ok := getCachedPages(playlist); ok {
+		return pages
+	}
 
-	for i := 0; i < int(numberOfRequests); i++ {
-		var tracksResults = new(playlistTracks)
+	pages := downloadAllTracks(playlist)
+	storeCachedPages(playlist, pages)
 
+	return pages
+}
+
+// downloadAllTracks downloads all the pages of tracks of a playlist using a limited amount of concurrent requests
+// (the --concurrency flag). The pages are returned in order
+func downloadAllTracks(playlist *playlist) []tracksPage {
+	numberOfPages := int(math.Ceil(float64(playlist.Tracks.Total) / utils.TracksLimit))
+	pages := make([]tracksPage, numberOfPages)
+	pageNumbers := make(chan int)
+	waitGroup := sync.WaitGroup{}
+
+	for i := 0; i < getConcurrency(numberOfPages); i++ {
 		waitGroup.Add(1)
 
-		go func(requestNumber int,
-			playlistId string,
-			tracks *playlistTracks,
-			term string,
-			results *[]table.Row,
-			textResults *[]textTable.Row,
-		) {
-			fetchTracks(requestNumber, playlist.Id, tracks)
-			executeSearch(tracks.Tracks, term, requestNumber, results, textResults)
-			waitGroup.Done()
-		}(i, playlist.Id, tracksResults, searchTerm, &results, &textResults)
+		go func() {
+			defer waitGroup.Done()
+
+			for pageNumber := range pageNumbers {
+				var tracksResults = new(playlistTracks)
+				err := fetchTracks(pageNumber, playlist.Id, tracksResults)
+
+				// Every worker writes into its own page, so there's no need to lock the slice
+				pages[pageNumber] = tracksPage{pageNumber, tracksResults.Tracks, err}
+			}
+		}()
 	}
 
+	for i := range pages {
+		pageNumbers <- i
+	}
+
+	close(pageNumbers)
 	waitGroup.Wait()
 
-	return results, textResults, nil
+	return pages
+}
+
+// getAllTracks returns every track of the playlist with its position. Unlike the search, which can show partial
+// results, it fails when any of the pages couldn't be fetched
+func getAllTracks(playlist *playlist) ([]playlistTrack, error) {
+	var tracks []playlistTrack
+
+	for _, page := range fetchAllTracks(playlist) {
+		if page.err != nil {
+			return nil, fmt.Errorf(utils.FetchTracksError, playlist.Name, page.err.Error())
+		}
+
+		for i, item := range page.tracks {
+			tracks = append(tracks, playlistTrack{page.number*utils.TracksLimit + i + 1, item.AddedAt, item.Track})
+		}
+	}
+
+	return tracks, nil
+}
+
+func resolvePlaylistTracks(reference string) (*playlist, []playlistTrack, error) {
+	playlist, err := resolvePlaylist(reference)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tracks, err := getAllTracks(playlist)
+
+	return playlist, tracks, err
+}
+
+func getConcurrency(numberOfPages int) int {
+	concurrency := utils.Options.Concurrency
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	if concurrency > numberOfPages {
+		concurrency = numberOfPages
+	}
+
+	return concurrency
 }
 
 func fetchTracks(requestNumber int, playlistId string, tracksResults *playlistTracks) error {
 	query := url.Values{
 		"limit":  {strconv.Itoa(utils.TracksLimit)},
 		"offset": {strconv.Itoa(requestNumber * utils.TracksLimit)},
-		"fields": {"items(track(name,id,artists(name,id)))"},
+		"fields": {utils.TrackFields},
 	}
-	url := fmt.Sprintf("%s/playlists/%s/tracks?%s", utils.SpotifyAPIBaseURL, playlistId, query.Encode())
+	url := fmt.Sprintf("%s/playlists/%s/tracks?%s", utils.APIBaseURL(), playlistId, query.Encode())
 
 	if err := MakeRequest(http.MethodGet, url, nil, tracksResults); err != nil {
 		return err
@@ -221,27 +446,37 @@ func fetchTracks(requestNumber int, playlistId string, tracksResults *playlistTr
 	return nil
 }
 
-func executeSearch(tracks []track, term string, requestNumber int, results *[]table.Row, textResults *[]textTable.Row) error {
+func executeSearch(tracks []track, term string, requestNumber int) []trackMatch {
+	var matches []trackMatch
 	var offset = requestNumber * utils.TracksLimit
 
 	for i, item := range tracks {
-		var artists []string
+		formattedArtists := item.Track.formattedArtists()
+		trackNameMatches, _ := matchTerm(term, item.Track.Name)
+		artistsMatch, _ := matchTerm(term, formattedArtists)
 
-		for _, artist := range item.Track.Artists {
-			artists = append(artists, artist.Name)
+		if trackNameMatches || artistsMatch {
+			matches = append(matches, trackMatch{offset + i + 1, item.Track.Name, formattedArtists, item.Track.Uri})
 		}
+	}
+
+	return matches
+}
 
-		formattedArtists := strings.Join(artists, ", ")
-		trackNameIncludesTerm := fuzzy.Match(term, strings.ToLower(item.Track.Name))
-		trackNameTermScore := CalculateJaroWinkler(term, strings.ToLower(item.Track.Name))
-		artistsIncludesTerm := fuzzy.Match(term, strings.ToLower(formattedArtists))
-		artistsTermScore := CalculateJaroWinkler(term, strings.ToLower(formattedArtists))
+// matchTerm tells if the value contains the (lowercase) term, using a fuzzy search and the Jaro-Winkler score
+func matchTerm(term string, value string) (bool, float64) {
+	lowercaseValue := strings.ToLower(value)
+	score := CalculateJaroWinkler(term, lowercaseValue)
 
-		if trackNameIncludesTerm || artistsIncludesTerm || trackNameTermScore > 0.8 || artistsTermScore > 0.8 {
-			*results = append(*results, table.Row{strconv.Itoa(offset + i + 1), item.Track.Name, formattedArtists})
-			*textResults = append(*textResults, textTable.Row{strconv.Itoa(offset + i + 1), item.Track.Name, formattedArtists})
-		}
+	return fuzzy.Match(term, lowercaseValue) || score > 0.8, score
+}
+
+func (info trackInfo) formattedArtists() string {
+	var artists []string
+
+	for _, artist := range info.Artists {
+		artists = append(artists, artist.Name)
 	}
 
-	return nil
+	return strings.Join(artists, ", ")
 }
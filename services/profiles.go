@@ -0,0 +1,119 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/CarlosGMI/Playlistify/utils"
+	"github.com/spf13/viper"
+)
+
+// GetProfiles lists the profiles of the configuration file, including the active one even if it has never been used
+func GetProfiles() TableResultsMsg {
+	var message = TableResultsMsg{TableType: utils.ProfilesTable}
+	var profiles = utils.GetProfiles()
+	var active = utils.ActiveProfile()
+
+	if !profileExists(active) {
+		profiles = append(profiles, active)
+	}
+
+	sort.Strings(profiles)
+
+	for _, profile := range profiles {
+		var playlists []playlist
+		var isActive string
+
+		if profile == active {
+			isActive = "*"
+		}
+
+		_ = viper.UnmarshalKey(utils.ProfileSettingKey(profile, "playlists"), &playlists)
+
+		message.appendRow([]string{
+			isActive,
+			profile,
+			viper.GetString(utils.ProfileSettingKey(profile, "user_id")),
+			strconv.Itoa(len(playlists)),
+		})
+	}
+
+	message.Title = fmt.Sprintf(utils.ProfilesTitle, len(profiles), active)
+
+	return message
+}
+
+// UseProfile makes the profile the active one for the next commands
+func UseProfile(profile string) error {
+	if err := utils.ValidateProfileName(profile); err != nil {
+		return err
+	}
+
+	if profile != utils.DefaultProfile && !profileExists(profile) {
+		return fmt.Errorf(utils.UnknownProfileError, profile, profile)
+	}
+
+	viper.Set("profile", profile)
+
+	return viper.WriteConfig()
+}
+
+// RemoveProfile deletes the credentials, settings, cached tracks and journal of the profile. The default profile is
+// active again when the removed profile was the active one
+func RemoveProfile(profile string) error {
+	var settings = []string{"profiles." + profile}
+
+	if err := utils.ValidateProfileName(profile); err != nil {
+		return err
+	}
+
+	if profile == utils.DefaultProfile {
+		return errors.New(utils.RemoveDefaultProfileError)
+	}
+
+	if !profileExists(profile) {
+		return fmt.Errorf(utils.UnknownProfileError, profile, profile)
+	}
+
+	store, err := newCredentialStore(profile)
+
+	if err != nil {
+		return err
+	}
+
+	if err := store.clear(); err != nil {
+		return fmt.Errorf(utils.CredentialsError, err.Error())
+	}
+
+	for _, getBaseDirectory := range []func() (string, error){os.UserConfigDir, os.UserCacheDir} {
+		directory, err := getBaseDirectory()
+
+		if err != nil {
+			return err
+		}
+
+		if err := os.RemoveAll(utils.ProfileDirectory(filepath.Join(directory, "playlistify"), profile)); err != nil {
+			return err
+		}
+	}
+
+	if viper.GetString("profile") == profile {
+		settings = append(settings, "profile")
+	}
+
+	return utils.RemoveSettings(settings...)
+}
+
+func profileExists(profile string) bool {
+	for _, name := range utils.GetProfiles() {
+		if name == profile {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/CarlosGMI/Playlistify/utils"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/viper"
+)
+
+type createPlaylistBody struct {
+	Name        string `json:"name"`
+	Public      bool   `json:"public"`
+	Description string `json:"description"`
+}
+
+// PlaylistSavedMsg is returned once the new playlist was created with its tracks
+type PlaylistSavedMsg struct {
+	Message string
+}
+
+// SaveSearchResults creates a private playlist for the user with the matched tracks of a search. Every track is
+// added once, and local files are skipped since they can't be added through the API. The new playlist is recorded
+// in the journal so its tracks can be removed with undo
+func SaveSearchResults(name string, results SearchResultsMsg) tea.Msg {
+	var newPlaylist = new(playlist)
+	var tracks []trackMatch
+	var uris []string
+	var seenTracks = map[string]bool{}
+	userId := viper.GetString(utils.ProfileKey("user_id"))
+
+	for _, match := range results.matches {
+		if match.uri != "" && !strings.HasPrefix(match.uri, "spotify:local:") && !seenTracks[match.uri] {
+			seenTracks[match.uri] = true
+			tracks = append(tracks, match)
+			uris = append(uris, match.uri)
+		}
+	}
+
+	if len(tracks) == 0 {
+		return PlaylistsErrorMsg{utils.NoTracksToSaveError}
+	}
+
+	if err := createPlaylist(name, userId, newPlaylist); err != nil {
+		return PlaylistsErrorMsg{err.Error()}
+	}
+
+	entry := journalEntry{
+		Operation:      utils.CreateOperation,
+		PlaylistId:     newPlaylist.Id,
+		PlaylistName:   newPlaylist.Name,
+		SnapshotBefore: newPlaylist.SnapshotId,
+	}
+	added, err := addTracks(newPlaylist, uris, 0)
+
+	for i, match := range tracks[:added] {
+		entry.Added = append(entry.Added, journalTrack{match.uri, i + 1, match.name, match.artists})
+	}
+
+	entry.SnapshotAfter = newPlaylist.SnapshotId
+
+	if journalErr := recordOperation(&entry); journalErr != nil {
+		return PlaylistsErrorMsg{fmt.Sprintf(utils.SaveJournalError, newPlaylist.Name, journalErr.Error())}
+	}
+
+	if err != nil {
+		return PlaylistsErrorMsg{fmt.Sprintf(utils.PartialSaveError, newPlaylist.Name, added, len(tracks), err.Error())}
+	}
+
+	return PlaylistSavedMsg{fmt.Sprintf(utils.PlaylistSavedMessage, newPlaylist.Name, added, entry.Id)}
+}
+
+func createPlaylist(name string, userId string, newPlaylist *playlist) error {
+	var url = fmt.Sprintf("%s/users/%s/playlists", utils.APIBaseURL(), userId)
+	var body = createPlaylistBody{Name: name, Public: false, Description: utils.PlaylistDescription}
+
+	if userId == "" {
+		return errors.New(utils.NotLoggedInError)
+	}
+
+	payload, err := json.Marshal(body)
+
+	if err != nil {
+		return err
+	}
+
+	if err := MakeRequest(http.MethodPost, url, bytes.NewReader(payload), newPlaylist); err != nil {
+		if isForbiddenError(err) {
+			return errors.New(utils.MissingModifyScopeError)
+		}
+
+		return fmt.Errorf(utils.CreatePlaylistError, name, err.Error())
+	}
+
+	return nil
+}
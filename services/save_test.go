@@ -0,0 +1,53 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/CarlosGMI/Playlistify/services/fakeserver"
+	"github.com/CarlosGMI/Playlistify/utils"
+)
+
+func TestSaveSearchResultsWithFakeServer(t *testing.T) {
+	useFakeServer(t, fakeserver.Options{TokenTTL: 3600})
+
+	search, ok := SearchInPlaylist("Road Trip", "numb").(SearchResultsMsg)
+
+	if !ok || len(search.matches) == 0 {
+		t.Fatalf("SearchInPlaylist() = %#v", search)
+	}
+
+	if msg, ok := SaveSearchResults("Numb", search).(PlaylistSavedMsg); !ok {
+		t.Fatalf("SaveSearchResults() = %#v", msg)
+	}
+
+	entries, err := readJournal()
+
+	if err != nil || len(entries) != 1 || entries[0].Operation != utils.CreateOperation {
+		t.Fatalf("the journal is %#v, %v, want the created playlist", entries, err)
+	}
+
+	saved, tracks, err := resolvePlaylistTracks(entries[0].PlaylistId)
+
+	if err != nil {
+		t.Fatalf("resolvePlaylistTracks() error = %v", err)
+	}
+
+	var savedUris, wantUris []string
+
+	for _, item := range tracks {
+		savedUris = append(savedUris, item.track.Uri)
+	}
+
+	for _, item := range entries[0].Added {
+		wantUris = append(wantUris, item.Uri)
+	}
+
+	if saved.Name != "Numb" || len(savedUris) == 0 || fmt.Sprint(savedUris) != fmt.Sprint(wantUris) {
+		t.Errorf("the saved playlist %q has the tracks %v, want %v", saved.Name, savedUris, wantUris)
+	}
+
+	if msg, ok := SaveSearchResults("Empty", SearchResultsMsg{}).(PlaylistsErrorMsg); !ok || msg.Message != utils.NoTracksToSaveError {
+		t.Errorf("SaveSearchResults() without tracks = %#v", msg)
+	}
+}
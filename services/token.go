@@ -0,0 +1,70 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/CarlosGMI/Playlistify/utils"
+)
+
+// tokenRefreshMargin is the number of seconds before the expiration in which the access token is already considered
+// expired and gets refreshed
+const tokenRefreshMargin = 60
+
+var tokenMutex sync.Mutex
+
+// getValidToken returns an access token that can be used right away, refreshing it first when it is about to expire
+func getValidToken() (string, error) {
+	tokenMutex.Lock()
+	defer tokenMutex.Unlock()
+
+	current, err := getCredentials()
+
+	if err != nil {
+		return "", err
+	}
+
+	if current.AccessToken == "" {
+		return "", errors.New(utils.NotLoggedInError)
+	}
+
+	if time.Now().Unix()+tokenRefreshMargin < current.Expiration {
+		return current.AccessToken, nil
+	}
+
+	return refreshAccessToken()
+}
+
+// forceTokenRefresh refreshes the access token after the API rejected it. If another request already rotated the
+// rejected token, the new one is returned
+func forceTokenRefresh(rejectedToken string) (string, error) {
+	tokenMutex.Lock()
+	defer tokenMutex.Unlock()
+
+	current, err := getCredentials()
+
+	if err != nil {
+		return "", err
+	}
+
+	if current.AccessToken != rejectedToken && current.AccessToken != "" {
+		return current.AccessToken, nil
+	}
+
+	return refreshAccessToken()
+}
+
+func refreshAccessToken() (string, error) {
+	token, err := requestSpotifyRefreshToken()
+
+	if err != nil {
+		return "", err
+	}
+
+	if err := storeTokenInformation(token); err != nil {
+		return "", err
+	}
+
+	return token.AccessToken, nil
+}
@@ -0,0 +1,230 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/CarlosGMI/Playlistify/utils"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// UndoOperation shows the changes that revert the operation of the journal, or the last one that wasn't undone
+// when the ID is empty. The changes are returned as a ConfirmationMsg. Unless force is enabled, the playlist can't
+// have changed after the operation, since the positions of the tracks wouldn't be valid anymore
+func UndoOperation(id string, force bool) tea.Msg {
+	var message = TableResultsMsg{TableType: utils.UndoTable}
+	entries, err := readJournal()
+
+	if err != nil {
+		return PlaylistsErrorMsg{err.Error()}
+	}
+
+	entry, err := findUndoableEntry(entries, id)
+
+	if err != nil {
+		return PlaylistsErrorMsg{err.Error()}
+	}
+
+	playlist, err := resolvePlaylist(entry.PlaylistId)
+
+	if err != nil {
+		return errorToMsg(err)
+	}
+
+	if expectedSnapshot, ok := getExpectedSnapshot(entries, entry); (!ok || playlist.SnapshotId != expectedSnapshot) && !force {
+		return PlaylistsErrorMsg{fmt.Sprintf(utils.PlaylistChangedError, playlist.Name, entry.Id)}
+	}
+
+	for _, item := range entry.Added {
+		message.appendRow([]string{utils.RemoveAction, strconv.Itoa(item.Position), item.Name, item.Artists})
+	}
+
+	for _, item := range entry.Removed {
+		message.appendRow([]string{utils.RestoreAction, strconv.Itoa(item.Position), item.Name, item.Artists})
+	}
+
+	message.Title = fmt.Sprintf(utils.UndoConfirmationTitle, entry.Id, entry.Operation, playlist.Name, len(entry.Removed), len(entry.Added))
+
+	return ConfirmationMsg{
+		TableResultsMsg: message,
+		ProgressText:    "Undoing the operation...",
+		Action: func() tea.Msg {
+			return undoOperation(playlist, *entry)
+		},
+	}
+}
+
+// findUndoableEntry returns the entry with the ID or, when it's empty, the last operation that wasn't undone. Undo
+// operations are skipped in that case so undoing several times keeps going back in the history, except the ones
+// that undo another undo, since they applied the original operation again
+func findUndoableEntry(entries []journalEntry, id string) (*journalEntry, error) {
+	if id == "" {
+		for i := len(entries) - 1; i >= 0; i-- {
+			if entries[i].UndoneBy == "" && getUndoDepth(entries, entries[i])%2 == 0 {
+				return &entries[i], nil
+			}
+		}
+
+		return nil, errors.New(utils.NothingToUndoError)
+	}
+
+	entry := findJournalEntry(entries, id)
+
+	if entry == nil {
+		return nil, fmt.Errorf(utils.UnknownOperationError, id)
+	}
+
+	if entry.UndoneBy != "" {
+		return nil, fmt.Errorf(utils.AlreadyUndoneError, id, entry.UndoneBy)
+	}
+
+	return entry, nil
+}
+
+// getExpectedSnapshot returns the snapshot the playlist has when it didn't change after the operation. The later
+// operations on the playlist don't count when all of them were undone, so several operations can be undone in a
+// row. It returns false when some of the later operations are still applied
+func getExpectedSnapshot(entries []journalEntry, entry *journalEntry) (string, bool) {
+	var laterOperations = map[string]bool{}
+	var expectedSnapshot = entry.SnapshotAfter
+	isLater := false
+
+	for i := range entries {
+		if &entries[i] == entry {
+			isLater = true
+
+			continue
+		}
+
+		if !isLater || entries[i].PlaylistId != entry.PlaylistId {
+			continue
+		}
+
+		laterOperations[entries[i].Id] = true
+		expectedSnapshot = entries[i].SnapshotAfter
+		root := &entries[i]
+		depth := 0
+
+		for root.Undoes != "" && findJournalEntry(entries, root.Undoes) != nil {
+			root = findJournalEntry(entries, root.Undoes)
+		}
+
+		if !laterOperations[root.Id] {
+			return "", false
+		}
+
+		for tip := root; tip.UndoneBy != "" && findJournalEntry(entries, tip.UndoneBy) != nil; tip = findJournalEntry(entries, tip.UndoneBy) {
+			depth++
+		}
+
+		if root == &entries[i] && depth%2 == 0 {
+			return "", false
+		}
+	}
+
+	return expectedSnapshot, true
+}
+
+// getUndoDepth counts the undo operations chained from the entry to the original operation, an even depth means
+// the original operation is applied
+func getUndoDepth(entries []journalEntry, entry journalEntry) int {
+	depth := 0
+
+	for current := &entry; current != nil && current.Undoes != ""; current = findJournalEntry(entries, current.Undoes) {
+		depth++
+	}
+
+	return depth
+}
+
+// undoOperation removes the added tracks and then restores the removed ones, which is the reverse order of the
+// operation. The undo is recorded in the journal like any other operation, even if it couldn't be completed
+func undoOperation(playlist *playlist, entry journalEntry) tea.Msg {
+	var message = TableResultsMsg{TableType: utils.UndoTable}
+	var undo = journalEntry{
+		Operation:      utils.UndoOperation,
+		PlaylistId:     playlist.Id,
+		PlaylistName:   playlist.Name,
+		SnapshotBefore: playlist.SnapshotId,
+		Undoes:         entry.Id,
+	}
+	var addedTracks []playlistTrack
+
+	for _, item := range entry.Added {
+		info := trackInfo{Uri: item.Uri, Name: item.Name, Artists: []trackArtist{{Name: item.Artists}}}
+		addedTracks = append(addedTracks, playlistTrack{position: item.Position, track: info})
+	}
+
+	removed, err := removeTracks(playlist, addedTracks)
+	undo.Removed = journalTracks(removed)
+
+	if err == nil {
+		undo.Added, err = restoreTracks(playlist, entry.Removed)
+	}
+
+	if len(undo.Removed) > 0 || len(undo.Added) > 0 {
+		undo.SnapshotAfter = playlist.SnapshotId
+
+		if journalErr := recordOperation(&undo); journalErr != nil {
+			return PlaylistsErrorMsg{fmt.Sprintf(utils.UndoJournalError, entry.Id, journalErr.Error())}
+		}
+	}
+
+	if err != nil && undo.Id == "" {
+		return PlaylistsErrorMsg{err.Error()}
+	}
+
+	if err != nil {
+		return PlaylistsErrorMsg{fmt.Sprintf(utils.PartialUndoError, err.Error(), entry.Id, undo.Id)}
+	}
+
+	if err := markAsUndone(entry.Id, undo.Id); err != nil {
+		return PlaylistsErrorMsg{err.Error()}
+	}
+
+	for _, item := range entry.Added {
+		message.appendRow([]string{utils.RemovedAction, strconv.Itoa(item.Position), item.Name, item.Artists})
+	}
+
+	for _, item := range undo.Added {
+		message.appendRow([]string{utils.RestoredAction, strconv.Itoa(item.Position), item.Name, item.Artists})
+	}
+
+	message.Title = fmt.Sprintf(utils.UndoTitle, entry.Id, playlist.Name, undo.Id)
+
+	return message
+}
+
+// restoreTracks inserts the removed tracks back at their positions. Going from the lowest to the highest position
+// every track lands where it was, and consecutive tracks are inserted together
+func restoreTracks(playlist *playlist, tracks []journalTrack) ([]journalTrack, error) {
+	var restored []journalTrack
+	sortedTracks := append([]journalTrack(nil), tracks...)
+
+	sort.Slice(sortedTracks, func(i, j int) bool {
+		return sortedTracks[i].Position < sortedTracks[j].Position
+	})
+
+	for start := 0; start < len(sortedTracks); {
+		var uris = []string{sortedTracks[start].Uri}
+		end := start + 1
+
+		for end < len(sortedTracks) && sortedTracks[end].Position == sortedTracks[end-1].Position+1 {
+			uris = append(uris, sortedTracks[end].Uri)
+			end++
+		}
+
+		added, err := addTracks(playlist, uris, sortedTracks[start].Position)
+		restored = append(restored, sortedTracks[start:start+added]...)
+
+		if err != nil {
+			return restored, err
+		}
+
+		start = end
+	}
+
+	return restored, nil
+}
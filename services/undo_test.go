@@ -0,0 +1,252 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/CarlosGMI/Playlistify/utils"
+)
+
+func TestFindUndoableEntry(t *testing.T) {
+	undone := []journalEntry{
+		{Id: "1", PlaylistId: "p1"},
+		{Id: "2", PlaylistId: "p1", UndoneBy: "3"},
+		{Id: "3", PlaylistId: "p1", Operation: utils.UndoOperation, Undoes: "2"},
+	}
+	redone := []journalEntry{
+		{Id: "1", PlaylistId: "p1"},
+		{Id: "2", PlaylistId: "p1", UndoneBy: "3"},
+		{Id: "3", PlaylistId: "p1", Operation: utils.UndoOperation, Undoes: "2", UndoneBy: "4"},
+		{Id: "4", PlaylistId: "p1", Operation: utils.UndoOperation, Undoes: "3"},
+	}
+
+	tests := []struct {
+		name    string
+		entries []journalEntry
+		id      string
+		want    string
+		wantErr string
+	}{
+		{"last operation", []journalEntry{{Id: "1"}, {Id: "2"}}, "", "2", ""},
+		{"skips the undone operations and the undos", undone, "", "1", ""},
+		{"undo of an undo", redone, "", "4", ""},
+		{"operation by id", undone, "1", "1", ""},
+		{"undo by id", undone, "3", "3", ""},
+		{"empty journal", nil, "", "", utils.NothingToUndoError},
+		{"everything undone", undone[1:], "", "", utils.NothingToUndoError},
+		{"unknown id", undone, "9", "", fmt.Sprintf(utils.UnknownOperationError, "9")},
+		{"already undone", undone, "2", "", fmt.Sprintf(utils.AlreadyUndoneError, "2", "3")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := findUndoableEntry(test.entries, test.id)
+
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Errorf("findUndoableEntry(%q) error = %v, want %q", test.id, err, test.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("findUndoableEntry(%q) error = %v", test.id, err)
+			}
+
+			if got.Id != test.want {
+				t.Errorf("findUndoableEntry(%q) = %q, want %q", test.id, got.Id, test.want)
+			}
+		})
+	}
+}
+
+func TestGetUndoDepth(t *testing.T) {
+	entries := []journalEntry{
+		{Id: "1", PlaylistId: "p1", UndoneBy: "2"},
+		{Id: "2", PlaylistId: "p1", Undoes: "1", UndoneBy: "3"},
+		{Id: "3", PlaylistId: "p1", Undoes: "2"},
+		{Id: "4", PlaylistId: "p1", Undoes: "9"},
+	}
+
+	tests := []struct {
+		name  string
+		entry int
+		want  int
+	}{
+		{"operation", 0, 0},
+		{"undo", 1, 1},
+		{"undo of an undo", 2, 2},
+		{"undo of an unknown operation", 3, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := getUndoDepth(entries, entries[test.entry]); got != test.want {
+				t.Errorf("getUndoDepth(%q) = %d, want %d", entries[test.entry].Id, got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetExpectedSnapshot(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []journalEntry
+		entry   int
+		want    string
+		wantOk  bool
+	}{
+		{
+			name: "last operation",
+			entries: []journalEntry{
+				{Id: "1", PlaylistId: "p1", SnapshotAfter: "s1"},
+			},
+			entry: 0, want: "s1", wantOk: true,
+		},
+		{
+			name: "later operations on other playlists",
+			entries: []journalEntry{
+				{Id: "1", PlaylistId: "p1", SnapshotAfter: "s1"},
+				{Id: "2", PlaylistId: "p2", SnapshotAfter: "s2"},
+			},
+			entry: 0, want: "s1", wantOk: true,
+		},
+		{
+			name: "later operation still applied",
+			entries: []journalEntry{
+				{Id: "1", PlaylistId: "p1", SnapshotAfter: "s1"},
+				{Id: "2", PlaylistId: "p1", SnapshotAfter: "s2"},
+			},
+			entry: 0, want: "", wantOk: false,
+		},
+		{
+			name: "later operation undone",
+			entries: []journalEntry{
+				{Id: "1", PlaylistId: "p1", SnapshotAfter: "s1"},
+				{Id: "2", PlaylistId: "p1", SnapshotAfter: "s2", UndoneBy: "3"},
+				{Id: "3", PlaylistId: "p1", SnapshotAfter: "s3", Undoes: "2"},
+			},
+			entry: 0, want: "s3", wantOk: true,
+		},
+		{
+			name: "later operation undone and applied again",
+			entries: []journalEntry{
+				{Id: "1", PlaylistId: "p1", SnapshotAfter: "s1"},
+				{Id: "2", PlaylistId: "p1", SnapshotAfter: "s2", UndoneBy: "3"},
+				{Id: "3", PlaylistId: "p1", SnapshotAfter: "s3", Undoes: "2", UndoneBy: "4"},
+				{Id: "4", PlaylistId: "p1", SnapshotAfter: "s4", Undoes: "3"},
+			},
+			entry: 0, want: "", wantOk: false,
+		},
+		{
+			name: "later undo of an earlier operation",
+			entries: []journalEntry{
+				{Id: "1", PlaylistId: "p1", SnapshotAfter: "s1", UndoneBy: "3"},
+				{Id: "2", PlaylistId: "p1", SnapshotAfter: "s2"},
+				{Id: "3", PlaylistId: "p1", SnapshotAfter: "s3", Undoes: "1"},
+			},
+			entry: 1, want: "", wantOk: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := getExpectedSnapshot(test.entries, &test.entries[test.entry])
+
+			if got != test.want || ok != test.wantOk {
+				t.Errorf("getExpectedSnapshot() = %q, %v, want %q, %v", got, ok, test.want, test.wantOk)
+			}
+		})
+	}
+}
+
+func TestRestoreTracks(t *testing.T) {
+	tests := []struct {
+		name         string
+		positions    []int
+		failAt       int
+		wantRequests []string
+		wantRestored []int
+		wantSnapshot string
+		wantErr      bool
+	}{
+		{
+			name:         "consecutive tracks are inserted together",
+			positions:    []int{5, 2, 10, 3, 9},
+			wantRequests: []string{"1 [2 3]", "4 [5]", "8 [9 10]"},
+			wantRestored: []int{2, 3, 5, 9, 10},
+			wantSnapshot: "s3",
+		},
+		{
+			name:         "a failed request stops the restore",
+			positions:    []int{1, 4, 7},
+			failAt:       2,
+			wantRequests: []string{"0 [1]", "3 [4]"},
+			wantRestored: []int{1},
+			wantSnapshot: "s1",
+			wantErr:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var requests []string
+			var tracks []journalTrack
+			var restoredPositions []int
+			var playlist = &playlist{Id: "p1", Name: "Road Trip", SnapshotId: "s0"}
+
+			useTestAPI(t, http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				var body addTracksBody
+				var positions []string
+
+				if err := json.NewDecoder(request.Body).Decode(&body); err != nil || body.Position == nil {
+					t.Errorf("invalid request body: %v", err)
+				}
+
+				for _, uri := range body.Uris {
+					positions = append(positions, strings.TrimPrefix(uri, "spotify:track:"))
+				}
+
+				requests = append(requests, fmt.Sprintf("%d %v", *body.Position, positions))
+
+				if len(requests) == test.failAt {
+					writer.WriteHeader(http.StatusInternalServerError)
+
+					return
+				}
+
+				fmt.Fprintf(writer, `{"snapshot_id": "s%d"}`, len(requests))
+			}))
+
+			for _, position := range test.positions {
+				tracks = append(tracks, journalTrack{Uri: fmt.Sprintf("spotify:track:%d", position), Position: position})
+			}
+
+			restored, err := restoreTracks(playlist, tracks)
+
+			for _, item := range restored {
+				restoredPositions = append(restoredPositions, item.Position)
+			}
+
+			if (err != nil) != test.wantErr {
+				t.Errorf("restoreTracks() error = %v, want error %v", err, test.wantErr)
+			}
+
+			if fmt.Sprint(requests) != fmt.Sprint(test.wantRequests) {
+				t.Errorf("restoreTracks() requests = %v, want %v", requests, test.wantRequests)
+			}
+
+			if fmt.Sprint(restoredPositions) != fmt.Sprint(test.wantRestored) {
+				t.Errorf("restoreTracks() restored = %v, want %v", restoredPositions, test.wantRestored)
+			}
+
+			if playlist.SnapshotId != test.wantSnapshot {
+				t.Errorf("the snapshot of the playlist is %q, want %q", playlist.SnapshotId, test.wantSnapshot)
+			}
+		})
+	}
+}
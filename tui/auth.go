@@ -1,11 +1,13 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/CarlosGMI/Playlistify/services"
 	"github.com/CarlosGMI/Playlistify/utils"
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -14,13 +16,21 @@ type AuthModel struct {
 	loader      spinner.Model
 	loaderText  string
 	resultsText string
+	// noBrowser prints the authorization URL and asks for the callback URL instead of opening the browser
+	noBrowser        bool
+	authorizationURL string
+	callbackInput    textinput.Model
+	callbackError    string
+	// cancelAuthorization stops waiting for the browser, it's only set while waiting
+	cancelAuthorization context.CancelFunc
 }
 
-func CreateAuthentication() AuthModel {
+func CreateAuthentication(noBrowser bool) AuthModel {
 	return AuthModel{
 		state:      utils.LoadingState,
 		loader:     CreateSpinner(),
 		loaderText: "Authenticating...",
+		noBrowser:  noBrowser,
 	}
 }
 
@@ -34,8 +44,21 @@ func (model *AuthModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if model.state == utils.InputState {
+			return model.updateCallbackInput(msg)
+		}
+
 		switch msg.String() {
 		case "ctrl+c", "q", "esc":
+			if model.cancelAuthorization != nil {
+				// The server is shut down before quitting, Authenticate returns the cancellation as an error
+				model.cancelAuthorization()
+				model.cancelAuthorization = nil
+				model.loaderText = "Cancelling..."
+
+				return model, nil
+			}
+
 			return model, tea.Quit
 		default:
 			if model.state == utils.ErrorState {
@@ -45,16 +68,30 @@ func (model *AuthModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return model, nil
 		}
 	case services.NotAuthenticatedMsg:
-		if msg.ErrorType == utils.NotLoggedInCode {
-			model.loaderText = "Authorizing..."
+		if msg.ErrorType == utils.NotLoggedInCode && model.noBrowser {
+			return model, startManualAuthorization()
+		} else if msg.ErrorType == utils.NotLoggedInCode {
+			var ctx context.Context
+			ctx, model.cancelAuthorization = context.WithCancel(context.Background())
+			model.loaderText = "Waiting for the authorization in the browser..."
 			model.loader, cmd = model.loader.Update(spinner.TickMsg{})
-			cmds = append(cmds, authenticate(), cmd)
+			cmds = append(cmds, authenticate(ctx), cmd)
 		} else if msg.ErrorType == utils.ExpiredTokenCode {
 			model.loaderText = "Refreshing token..."
 			model.loader, cmd = model.loader.Update(spinner.TickMsg{})
 			cmds = append(cmds, refreshAuth(), cmd)
 		}
+	case services.AuthorizationURLMsg:
+		model.state = utils.InputState
+		model.authorizationURL = string(msg)
+		model.callbackInput = textinput.New()
+		model.callbackInput.Placeholder = utils.CallbackURL() + "?code=..."
+		model.callbackInput.Width = 60
+		model.callbackInput.Focus()
+
+		return model, textinput.Blink
 	case services.AuthorizedMsg:
+		model.cancelAuthorization = nil
 		model.loaderText = "Logging in..."
 		model.loader, cmd = model.loader.Update(spinner.TickMsg{})
 		cmds = append(cmds, login(), cmd)
@@ -73,6 +110,11 @@ func (model *AuthModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			model.loader, cmd = model.loader.Update(spinner.TickMsg{})
 			cmds = append(cmds, fetchUser(), cmd)
 		} else {
+			if model.cancelAuthorization != nil {
+				model.cancelAuthorization()
+				model.cancelAuthorization = nil
+			}
+
 			model.state = utils.ErrorState
 			model.resultsText = msg.Message
 
@@ -91,17 +133,70 @@ func (model *AuthModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (model AuthModel) View() string {
-	if model.state == utils.LoadingState {
+	if model.state == utils.LoadingState && model.cancelAuthorization != nil {
+		return fmt.Sprintf("\n %s %s\n\n%s\n", model.loader.View(), model.loaderText, utils.HelpStyle(" esc: cancel"))
+	} else if model.state == utils.LoadingState {
 		return fmt.Sprintf("\n %s %s\n\n", model.loader.View(), model.loaderText)
 	} else if model.state == utils.ErrorState {
 		return fmt.Sprintf("\n %s%s\n\n", utils.ErrorStyle("Error: "), model.resultsText)
+	} else if model.state == utils.InputState {
+		return model.callbackView()
 	}
 
 	return fmt.Sprintf("\n %s\n\n", model.resultsText)
 }
 
-func authenticate() tea.Cmd {
-	return services.Authenticate
+// updateCallbackInput reads the callback URL pasted by the user. An invalid URL can be pasted again
+func (model *AuthModel) updateCallbackInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg.String() {
+	case "ctrl+c", "esc":
+		return model, tea.Quit
+	case "enter":
+		switch result := services.CompleteManualAuthorization(model.callbackInput.Value()).(type) {
+		case services.AuthErrorMsg:
+			model.callbackError = result.Message
+			model.callbackInput.SetValue("")
+
+			return model, nil
+		default:
+			model.state = utils.LoadingState
+			model.callbackError = ""
+			model.loader, cmd = model.loader.Update(spinner.TickMsg{})
+
+			return model.Update(result)
+		}
+	}
+
+	model.callbackInput, cmd = model.callbackInput.Update(msg)
+
+	return model, cmd
+}
+
+func (model AuthModel) callbackView() string {
+	content := fmt.Sprintf(
+		"\n Open this URL in a browser on any device and authorize Playlistify:\n\n%s\n\n %s\n\n%s\n\n",
+		model.authorizationURL,
+		"Then paste the URL you were redirected to (the page won't load), or its code parameter:",
+		model.callbackInput.View(),
+	)
+
+	if len(model.callbackError) > 0 {
+		content += fmt.Sprintf(" %s%s\n\n", utils.ErrorStyle("Error: "), model.callbackError)
+	}
+
+	return content + utils.HelpStyle(" enter: continue • esc: cancel") + "\n"
+}
+
+func authenticate(ctx context.Context) tea.Cmd {
+	return func() tea.Msg {
+		return services.Authenticate(ctx)
+	}
+}
+
+func startManualAuthorization() tea.Cmd {
+	return services.StartManualAuthorization
 }
 
 func login() tea.Cmd {
@@ -0,0 +1,190 @@
+package tui
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/CarlosGMI/Playlistify/utils"
+	textTable "github.com/jedib0t/go-pretty/v6/table"
+)
+
+type outputColumn struct {
+	key     string
+	numeric bool
+}
+
+var outputColumns = map[string][]outputColumn{
+	TableTypes[0]: {
+		{"playlist_id", true},
+		{"name", false},
+		{"total_tracks", true},
+	},
+	TableTypes[1]: {
+		{"position", true},
+		{"name", false},
+		{"artists", false},
+	},
+	TableTypes[2]: {
+		{"playlist", false},
+		{"position", true},
+		{"name", false},
+		{"artists", false},
+	},
+	TableTypes[3]: {
+		{"group", true},
+		{"position", true},
+		{"name", false},
+		{"artists", false},
+		{"reason", false},
+	},
+	TableTypes[4]: {
+		{"playlist_id", false},
+		{"name", false},
+		{"tracks", true},
+		{"size", false},
+		{"updated_at", false},
+	},
+	TableTypes[5]: {
+		{"status", false},
+		{"entry", false},
+		{"position", true},
+		{"match", false},
+		{"score", true},
+	},
+	TableTypes[6]: {
+		{"status", false},
+		{"position_a", true},
+		{"position_b", true},
+		{"name", false},
+		{"artists", false},
+		{"match", false},
+	},
+	TableTypes[7]: {
+		{"action", false},
+		{"group", true},
+		{"position", true},
+		{"name", false},
+		{"artists", false},
+		{"reason", false},
+	},
+	TableTypes[8]: {
+		{"action", false},
+		{"position", true},
+		{"name", false},
+		{"artists", false},
+	},
+	TableTypes[9]: {
+		{"id", false},
+		{"date", false},
+		{"operation", false},
+		{"playlist", false},
+		{"removed", true},
+		{"added", true},
+		{"undone_by", false},
+	},
+	TableTypes[10]: {
+		{"action", false},
+		{"entry", false},
+		{"name", false},
+		{"artists", false},
+		{"duplicate", false},
+	},
+	TableTypes[11]: {
+		{"action", false},
+		{"source", false},
+		{"position", true},
+		{"name", false},
+		{"artists", false},
+		{"reason", false},
+	},
+	TableTypes[12]: {
+		{"active", false},
+		{"profile", false},
+		{"user_id", false},
+		{"playlists", true},
+	},
+}
+
+// PrintOutput writes the rows of a table to the standard output using one of the non-interactive formats of the
+// --output flag
+func PrintOutput(format string, tableType string, rows []textTable.Row) error {
+	return writeOutput(os.Stdout, format, tableType, rows)
+}
+
+func writeOutput(writer io.Writer, format string, tableType string, rows []textTable.Row) error {
+	switch format {
+	case utils.OutputJSON:
+		return writeJSON(writer, tableType, rows)
+	case utils.OutputCSV:
+		return writeSeparatedValues(writer, tableType, rows, ',')
+	case utils.OutputTSV:
+		return writeSeparatedValues(writer, tableType, rows, '\t')
+	case utils.OutputTable:
+		_, err := fmt.Fprintln(writer, textView(tableType, rows))
+
+		return err
+	default:
+		return fmt.Errorf(utils.InvalidOutputError, format)
+	}
+}
+
+func writeJSON(writer io.Writer, tableType string, rows []textTable.Row) error {
+	var items = []map[string]interface{}{}
+	currentColumns := outputColumns[tableType]
+
+	for _, row := range rows {
+		item := map[string]interface{}{}
+
+		for i, column := range currentColumns {
+			value := fmt.Sprint(row[i])
+			item[column.key] = value
+
+			if number, err := strconv.ParseFloat(value, 64); column.numeric && err == nil {
+				item[column.key] = number
+			} else if column.numeric && value == "" {
+				item[column.key] = nil
+			}
+		}
+
+		items = append(items, item)
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(items)
+}
+
+func writeSeparatedValues(writer io.Writer, tableType string, rows []textTable.Row, separator rune) error {
+	var header []string
+	csvWriter := csv.NewWriter(writer)
+	csvWriter.Comma = separator
+
+	for _, column := range outputColumns[tableType] {
+		header = append(header, column.key)
+	}
+
+	if err := csvWriter.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		var record []string
+
+		for _, value := range row {
+			record = append(record, fmt.Sprint(value))
+		}
+
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+
+	return csvWriter.Error()
+}
@@ -0,0 +1,134 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/CarlosGMI/Playlistify/services"
+	"github.com/CarlosGMI/Playlistify/utils"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ResultsModel runs a command that doesn't need any input from the user (like dupes) and shows its results table.
+// Commands that make changes show them first and only run their action once the user confirms it
+type ResultsModel struct {
+	state       string
+	loader      spinner.Model
+	loaderText  string
+	command     tea.Cmd
+	results     TableModel
+	resultsText string
+}
+
+const confirmationState = "confirmation"
+
+func CreateResultsModel(loaderText string, command tea.Cmd) ResultsModel {
+	return ResultsModel{
+		state:      "",
+		loader:     CreateSpinner(),
+		loaderText: loaderText,
+		command:    command,
+	}
+}
+
+func (model ResultsModel) Init() tea.Cmd {
+	return services.InitAuthentication
+}
+
+func (model ResultsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if model.state == confirmationState {
+			return model.updateConfirmation(msg)
+		}
+
+		return model, tea.Quit
+	case services.NotAuthenticatedMsg:
+		if msg.ErrorType == utils.NotLoggedInCode {
+			model.state = utils.ErrorState
+			model.resultsText = msg.Message
+
+			return model, tea.Quit
+		}
+
+		// An expired token is refreshed by the API client, so the command can run right away
+		return model.runCommand()
+	case services.AuthErrorMsg:
+		return model.runCommand()
+	case services.TableResultsMsg:
+		model.state = "table"
+		model.results = CreateTable(msg.TableType, msg.Results, msg.TextResults, false, msg.Title, tableContext{})
+
+		return model.results.Update(msg)
+	case services.ConfirmationMsg:
+		model.state = confirmationState
+		model.results = CreateTable(msg.TableType, msg.Results, msg.TextResults, false, msg.Title, tableContext{})
+		model.command = msg.Action
+		model.loaderText = msg.ProgressText
+
+		return model, nil
+	case services.AmbiguousPlaylistMsg:
+		model.state = utils.ErrorState
+		model.resultsText = msg.Message
+
+		return model, tea.Quit
+	case services.PlaylistsErrorMsg:
+		model.state = utils.ErrorState
+		model.resultsText = msg.Message
+
+		return model, tea.Quit
+	case spinner.TickMsg:
+		model.loader, cmd = model.loader.Update(msg)
+
+		return model, cmd
+	}
+
+	return model, nil
+}
+
+func (model ResultsModel) View() string {
+	if model.state == utils.LoadingState {
+		return fmt.Sprintf("\n %s %s\n\n", model.loader.View(), model.loaderText)
+	} else if model.state == utils.ErrorState {
+		return fmt.Sprintf("\n %s%s\n\n", utils.ErrorStyle("Error: "), model.resultsText)
+	} else if model.state == utils.SuccessState {
+		return fmt.Sprintf("\n %s\n\n", model.resultsText)
+	} else if model.state == confirmationState {
+		return model.results.View() + utils.HelpStyle(" y: confirm the changes • n: cancel") + "\n\n"
+	}
+
+	return ""
+}
+
+// updateConfirmation runs the action when the user confirms the changes, the table keeps handling the keys to
+// move through the changes
+func (model ResultsModel) updateConfirmation(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var results tea.Model
+	var cmd tea.Cmd
+
+	switch msg.String() {
+	case "y":
+		return model.runCommand()
+	case "n", "q", "esc", "ctrl+c":
+		model.state = utils.SuccessState
+		model.resultsText = "No changes were made"
+
+		return model, tea.Quit
+	}
+
+	results, cmd = model.results.Update(msg)
+	model.results = results.(TableModel)
+
+	return model, cmd
+}
+
+func (model ResultsModel) runCommand() (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	model.state = utils.LoadingState
+	model.loader, cmd = model.loader.Update(spinner.TickMsg{})
+
+	return model, tea.Batch(model.command, cmd)
+}
@@ -0,0 +1,114 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/CarlosGMI/Playlistify/services"
+	"github.com/CarlosGMI/Playlistify/utils"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// SaveModel asks for the name of the playlist where the results of a search are saved
+type SaveModel struct {
+	state       string
+	loader      spinner.Model
+	nameInput   textinput.Model
+	nameError   string
+	results     services.SearchResultsMsg
+	resultsText string
+}
+
+func CreateSaveModel(defaultName string, results services.SearchResultsMsg) SaveModel {
+	model := SaveModel{
+		state:     utils.InputState,
+		loader:    CreateSpinner(),
+		nameInput: createSearchInput(),
+		results:   results,
+	}
+	model.nameInput.Width = 40
+	model.nameInput.SetValue(defaultName)
+
+	return model
+}
+
+func (model SaveModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (model SaveModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if model.state != utils.InputState {
+			return model, tea.Quit
+		}
+
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			return model, tea.Quit
+		case "enter":
+			name := strings.TrimSpace(model.nameInput.Value())
+
+			if name == "" {
+				model.nameError = "the name of the playlist can't be empty"
+
+				return model, nil
+			}
+
+			model.state = utils.LoadingState
+			model.loader, cmd = model.loader.Update(spinner.TickMsg{})
+
+			return model, tea.Batch(saveSearchResults(name, model.results), cmd)
+		}
+
+		model.nameInput, cmd = model.nameInput.Update(msg)
+
+		return model, cmd
+	case services.PlaylistSavedMsg:
+		model.state = utils.SuccessState
+		model.resultsText = msg.Message
+
+		return model, tea.Quit
+	case services.PlaylistsErrorMsg:
+		model.state = utils.ErrorState
+		model.resultsText = msg.Message
+
+		return model, tea.Quit
+	case spinner.TickMsg:
+		model.loader, cmd = model.loader.Update(msg)
+
+		return model, cmd
+	}
+
+	model.nameInput, cmd = model.nameInput.Update(msg)
+
+	return model, cmd
+}
+
+func (model SaveModel) View() string {
+	if model.state == utils.LoadingState {
+		return fmt.Sprintf("\n %s %s\n\n", model.loader.View(), utils.SavingPlaylistText)
+	} else if model.state == utils.ErrorState {
+		return fmt.Sprintf("\n %s%s\n\n", utils.ErrorStyle("Error: "), model.resultsText)
+	} else if model.state == utils.SuccessState {
+		return fmt.Sprintf("\n %s\n\n", model.resultsText)
+	}
+
+	content := fmt.Sprintf("\n %s \n\n%s\n\n", "Enter the name of the new playlist:", model.nameInput.View())
+
+	if len(model.nameError) > 0 {
+		content += fmt.Sprintf(" %s%s\n\n", utils.ErrorStyle("Error: "), model.nameError)
+	}
+
+	return content
+}
+
+func saveSearchResults(name string, results services.SearchResultsMsg) tea.Cmd {
+	return func() tea.Msg {
+		return services.SaveSearchResults(name, results)
+	}
+}
@@ -23,6 +23,7 @@ type SearchModel struct {
 	searchTermError  string
 	results          TableModel
 	resultsText      string
+	allPlaylists     bool
 }
 
 func CreateSearchModel(showPlaylists bool, playlistId string, searchTerm string) SearchModel {
@@ -38,6 +39,14 @@ func CreateSearchModel(showPlaylists bool, playlistId string, searchTerm string)
 	return model
 }
 
+// CreateSearchAllModel creates a search that looks for the term in all the playlists listed by the list command
+func CreateSearchAllModel(searchTerm string) SearchModel {
+	model := CreateSearchModel(false, "", searchTerm)
+	model.allPlaylists = true
+
+	return model
+}
+
 func (model SearchModel) Init() tea.Cmd {
 	return services.InitAuthentication
 }
@@ -70,7 +79,7 @@ func (model SearchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				model.state = utils.LoadingState
 				model.loaderText = utils.SearchingText
 				model.loader, cmd = model.loader.Update(spinner.TickMsg{})
-				cmds = append(cmds, executeSearch(model.selectedPlaylist, model.searchTerm), cmd)
+				cmds = append(cmds, executeSearch(model.selectedPlaylist, model.searchTerm, model.allPlaylists), cmd)
 			}
 		default:
 			if model.searchInput.Focused() {
@@ -102,7 +111,7 @@ func (model SearchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		} else {
 			if len(model.searchTerm) > 0 {
 				model.loaderText = utils.SearchingText
-				cmds = append(cmds, executeSearch(model.selectedPlaylist, model.searchTerm), cmd)
+				cmds = append(cmds, executeSearch(model.selectedPlaylist, model.searchTerm, model.allPlaylists), cmd)
 			} else {
 				return model.Update(SelectedItemMsg{model.selectedPlaylist})
 			}
@@ -123,10 +132,22 @@ func (model SearchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			playlists,
 			textPlaylists,
 			true,
-			"Select the playlist to search:",
+			"Select the playlist to search (or press \"a\" to search in all of them):",
 			tableContext{},
 		)
 
+		return model.playlists.Update(msg)
+	case services.AmbiguousPlaylistMsg:
+		model.state = "table"
+		model.playlists = CreateTable(
+			utils.PlaylistsTable,
+			msg.Results,
+			msg.TextRows,
+			true,
+			fmt.Sprintf("Several playlists match %q, select the one to search (or press \"a\" to search in all of them):", model.selectedPlaylist),
+			tableContext{searchTerm: model.searchTerm},
+		)
+
 		return model.playlists.Update(msg)
 	case SelectedItemMsg:
 		model.selectedPlaylist = msg.Item
@@ -135,15 +156,37 @@ func (model SearchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		model.searchInput, cmd = model.searchInput.Update(msg)
 		cmds = append(cmds, cmd)
 	case services.SearchResultsMsg:
+		if msg.Status == utils.SearchFailed {
+			model.state = utils.ErrorState
+			model.resultsText = fmt.Sprintf(utils.SearchFailedError, msg.Error)
+
+			return model, tea.Quit
+		}
+
+		tableType := utils.SongsTable
 		previewText := fmt.Sprintf("\nSelected playlist: %s", msg.PlaylistName)
+
+		if model.allPlaylists {
+			tableType = utils.AllSongsTable
+		}
+
+		if msg.Status == utils.SearchPartial {
+			previewText += fmt.Sprintf("\n%s%s", utils.ErrorStyle("Warning: "), msg.Warning())
+		}
+
 		model.state = "table"
 		model.results = CreateTable(
-			utils.SongsTable,
+			tableType,
 			msg.Results,
 			msg.TextResults,
 			false,
 			previewText,
-			tableContext{model.selectedPlaylist},
+			tableContext{
+				selectedPlaylist: model.selectedPlaylist,
+				allPlaylists:     model.allPlaylists,
+				searchResults:    &msg,
+				saveName:         fmt.Sprintf("%s in %s", model.searchTerm, msg.PlaylistName),
+			},
 		)
 
 		return model.results.Update(msg)
@@ -196,8 +239,12 @@ func isSearchTermValid(term string) bool {
 	return len(termNoWhitespace) >= 3
 }
 
-func executeSearch(playlistId string, term string) tea.Cmd {
+func executeSearch(playlistId string, term string, allPlaylists bool) tea.Cmd {
 	return func() tea.Msg {
+		if allPlaylists {
+			return services.SearchInAllPlaylists(term)
+		}
+
 		return services.SearchInPlaylist(playlistId, term)
 	}
 }
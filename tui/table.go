@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/CarlosGMI/Playlistify/services"
 	"github.com/CarlosGMI/Playlistify/utils"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/table"
@@ -18,6 +19,8 @@ type tableKeymap struct {
 	newSearch           key.Binding
 	newSearchInPlaylist key.Binding
 	switchMode          key.Binding
+	searchAll           key.Binding
+	saveResults         key.Binding
 }
 type tableHelpOption struct {
 	character   string
@@ -26,6 +29,12 @@ type tableHelpOption struct {
 }
 type tableContext struct {
 	selectedPlaylist string
+	// searchTerm is set when the table is used to pick a playlist for a term the user already entered
+	searchTerm   string
+	allPlaylists bool
+	// searchResults are the results shown by the table, which can be saved as a new playlist
+	searchResults *services.SearchResultsMsg
+	saveName      string
 }
 type TableModel struct {
 	table       table.Model
@@ -56,16 +65,26 @@ var tableKeys = tableKeymap{
 		key.WithKeys("s"),
 		key.WithHelp("s", "Switch table mode"),
 	),
+	searchAll: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "Search in all playlists"),
+	),
+	saveResults: key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "Save the results as a new playlist"),
+	),
 }
 var tableHelpOptions = []tableHelpOption{
 	{"n", "new search", true},
 	{"p", "new search in current playlist", true},
 	{"s", "switch to text view", true},
 	{"s", "switch to table view", false},
+	{"c", "save as a new playlist", false},
+	{"a", "search in all playlists", false},
 	{"q", "quit", true},
 	{"esc", "quit", true},
 }
-var TableTypes = []string{utils.PlaylistsTable, utils.SongsTable}
+var TableTypes = []string{utils.PlaylistsTable, utils.SongsTable, utils.AllSongsTable, utils.DuplicatesTable, utils.CacheTable, utils.CheckTable, utils.DiffTable, utils.DedupeTable, utils.UndoTable, utils.HistoryTable, utils.AddTable, utils.MergeTable, utils.ProfilesTable}
 var tableBaseStyle = lipgloss.NewStyle().BorderStyle(lipgloss.NormalBorder()).BorderForeground(lipgloss.Color("240"))
 var columns = map[string][]table.Column{
 	TableTypes[0]: {
@@ -78,6 +97,85 @@ var columns = map[string][]table.Column{
 		{Title: "NAME", Width: 50},
 		{Title: "ARTISTS", Width: 50},
 	},
+	TableTypes[2]: {
+		{Title: "PLAYLIST", Width: 30},
+		{Title: "#", Width: 8},
+		{Title: "NAME", Width: 40},
+		{Title: "ARTISTS", Width: 40},
+	},
+	TableTypes[3]: {
+		{Title: "GROUP", Width: 8},
+		{Title: "#", Width: 8},
+		{Title: "NAME", Width: 40},
+		{Title: "ARTISTS", Width: 30},
+		{Title: "REASON", Width: 30},
+	},
+	TableTypes[4]: {
+		{Title: "PLAYLIST ID", Width: 24},
+		{Title: "PLAYLIST NAME", Width: 40},
+		{Title: "TRACKS", Width: 10},
+		{Title: "SIZE", Width: 10},
+		{Title: "UPDATED AT", Width: 26},
+	},
+	TableTypes[5]: {
+		{Title: "STATUS", Width: 12},
+		{Title: "ENTRY", Width: 40},
+		{Title: "#", Width: 6},
+		{Title: "MATCH", Width: 50},
+		{Title: "SCORE", Width: 8},
+	},
+	TableTypes[6]: {
+		{Title: "STATUS", Width: 10},
+		{Title: "A #", Width: 6},
+		{Title: "B #", Width: 6},
+		{Title: "NAME", Width: 40},
+		{Title: "ARTISTS", Width: 30},
+		{Title: "MATCH", Width: 30},
+	},
+	TableTypes[7]: {
+		{Title: "ACTION", Width: 8},
+		{Title: "GROUP", Width: 8},
+		{Title: "#", Width: 8},
+		{Title: "NAME", Width: 40},
+		{Title: "ARTISTS", Width: 30},
+		{Title: "REASON", Width: 30},
+	},
+	TableTypes[8]: {
+		{Title: "ACTION", Width: 10},
+		{Title: "#", Width: 8},
+		{Title: "NAME", Width: 50},
+		{Title: "ARTISTS", Width: 40},
+	},
+	TableTypes[9]: {
+		{Title: "OPERATION ID", Width: 20},
+		{Title: "DATE", Width: 20},
+		{Title: "OPERATION", Width: 26},
+		{Title: "PLAYLIST", Width: 30},
+		{Title: "REMOVED", Width: 8},
+		{Title: "ADDED", Width: 8},
+		{Title: "UNDONE BY", Width: 20},
+	},
+	TableTypes[10]: {
+		{Title: "ACTION", Width: 8},
+		{Title: "ENTRY", Width: 30},
+		{Title: "NAME", Width: 30},
+		{Title: "ARTISTS", Width: 24},
+		{Title: "DUPLICATE", Width: 40},
+	},
+	TableTypes[11]: {
+		{Title: "ACTION", Width: 8},
+		{Title: "SOURCE", Width: 24},
+		{Title: "#", Width: 6},
+		{Title: "NAME", Width: 30},
+		{Title: "ARTISTS", Width: 24},
+		{Title: "REASON", Width: 40},
+	},
+	TableTypes[12]: {
+		{Title: "ACTIVE", Width: 8},
+		{Title: "PROFILE", Width: 24},
+		{Title: "USER ID", Width: 30},
+		{Title: "PLAYLISTS", Width: 10},
+	},
 }
 
 func CreateTable(
@@ -89,11 +187,14 @@ func CreateTable(
 	context tableContext,
 ) TableModel {
 	var tableHeight = defaultTableHeight
-	isSearchTable := tableType == TableTypes[1]
-	isSongsTable := tableType == TableTypes[0]
-	showHelp := isSearchTable || (isSongsTable && !updatable)
+	isSearchTable := tableType == TableTypes[1] || tableType == TableTypes[2]
+	showHelp := isSearchTable || !updatable
 	tableHelpOptions[0].condition = isSearchTable
 	tableHelpOptions[1].condition = isSearchTable
+	tableHelpOptions[4].condition = isSearchTable && context.searchResults != nil && len(rows) > 0
+	// The playlist picker can't switch to the text view, but it can search in all the playlists
+	tableHelpOptions[2].condition = !updatable
+	tableHelpOptions[5].condition = updatable && tableType == TableTypes[0]
 	terminalWidth, _, err := term.GetSize(0)
 
 	if err != nil {
@@ -149,6 +250,12 @@ func (model TableModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "ctrl+c", "q", "esc":
 			return model, tea.Quit
 		case "enter":
+			if model.updatable && len(model.context.searchTerm) > 0 {
+				searchModel := CreateSearchModel(false, model.table.SelectedRow()[0], model.context.searchTerm)
+
+				return searchModel, searchModel.Init()
+			}
+
 			if model.updatable {
 				searchModel := CreateSearchModel(true, model.table.SelectedRow()[0], "")
 				msg := SelectedItemMsg{model.table.SelectedRow()[0]}
@@ -170,17 +277,35 @@ func (model TableModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		switch {
 		case key.Matches(msg, tableKeys.newSearch):
-			if model.showHelp {
+			if model.showHelp && model.allowsNewSearch() {
 				searchModel := CreateSearchModel(true, "", "")
 
 				return searchModel, searchModel.Init()
 			}
 		case key.Matches(msg, tableKeys.newSearchInPlaylist):
-			if model.showHelp {
+			if model.showHelp && model.allowsNewSearch() && model.context.allPlaylists {
+				searchModel := CreateSearchAllModel("")
+
+				return searchModel, searchModel.Init()
+			}
+
+			if model.showHelp && model.allowsNewSearch() {
 				searchModel := CreateSearchModel(false, model.context.selectedPlaylist, "")
 
 				return searchModel, searchModel.Init()
 			}
+		case key.Matches(msg, tableKeys.searchAll):
+			if model.updatable {
+				searchModel := CreateSearchAllModel(model.context.searchTerm)
+
+				return searchModel, searchModel.Init()
+			}
+		case key.Matches(msg, tableKeys.saveResults):
+			if model.context.searchResults != nil && len(model.table.Rows()) > 0 {
+				saveModel := CreateSaveModel(model.context.saveName, *model.context.searchResults)
+
+				return saveModel, saveModel.Init()
+			}
 		case key.Matches(msg, tableKeys.switchMode):
 			if !model.updatable {
 				if model.mode == utils.TableModeDefault {
@@ -220,13 +345,23 @@ func (model TableModel) View() string {
 		content += fmt.Sprintf("%s\n\n", model.viewport.View())
 	}
 
-	if model.showHelp {
+	if model.showHelp || model.isPlaylistPicker() {
 		content += model.helpView()
 	}
 
 	return fmt.Sprintf("\n%s\n\n", content)
 }
 
+// allowsNewSearch tells if the n and p keys can start a new search from this table
+func (model TableModel) allowsNewSearch() bool {
+	return model.tableType == TableTypes[0] || model.tableType == TableTypes[1] || model.tableType == TableTypes[2]
+}
+
+// isPlaylistPicker tells if the table is the list of playlists where the one to search is selected
+func (model TableModel) isPlaylistPicker() bool {
+	return model.updatable && model.tableType == TableTypes[0]
+}
+
 func (model TableModel) helpView() string {
 	var optionsToShow []string
 
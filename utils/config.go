@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// getSetting returns the value of the environment variable, the configuration file or the default value, in that
+// order. The environment variables are read directly so viper never writes them to the configuration file
+func getSetting(key string, environmentVariable string, defaultValue string) string {
+	if value := os.Getenv(environmentVariable); value != "" {
+		return value
+	}
+
+	if value := viper.GetString(key); value != "" {
+		return value
+	}
+
+	return defaultValue
+}
+
+// APIBaseURL is the base URL of the Spotify Web API, it can be changed with the api_base_url setting or the
+// PLAYLISTIFY_API_BASE_URL environment variable
+func APIBaseURL() string {
+	return strings.TrimSuffix(getSetting("api_base_url", "PLAYLISTIFY_API_BASE_URL", SpotifyAPIBaseURL), "/")
+}
+
+// AccountsBaseURL is the base URL of the Spotify Accounts service, it can be changed with the accounts_base_url
+// setting or the PLAYLISTIFY_ACCOUNTS_BASE_URL environment variable
+func AccountsBaseURL() string {
+	return strings.TrimSuffix(getSetting("accounts_base_url", "PLAYLISTIFY_ACCOUNTS_BASE_URL", SpotifyAccountBaseURL), "/")
+}
+
+// ClientId is the ID of the Spotify app Playlistify authorizes, it can be changed with the client_id setting or the
+// PLAYLISTIFY_CLIENT_ID environment variable to use your own app
+func ClientId() string {
+	return getSetting("client_id", "PLAYLISTIFY_CLIENT_ID", DefaultClientId)
+}
+
+// CallbackHost can be changed with the callback_host setting or the PLAYLISTIFY_CALLBACK_HOST environment variable
+func CallbackHost() string {
+	return getSetting("callback_host", "PLAYLISTIFY_CALLBACK_HOST", AuthorizationHost)
+}
+
+// CallbackPort can be changed with the callback_port setting or the PLAYLISTIFY_CALLBACK_PORT environment variable
+func CallbackPort() string {
+	return getSetting("callback_port", "PLAYLISTIFY_CALLBACK_PORT", AuthorizationPort)
+}
+
+// CallbackPath can be changed with the callback_path setting or the PLAYLISTIFY_CALLBACK_PATH environment variable
+func CallbackPath() string {
+	return "/" + strings.TrimPrefix(getSetting("callback_path", "PLAYLISTIFY_CALLBACK_PATH", AuthorizationCallbackEndpoint), "/")
+}
+
+// CallbackURL is the redirect URI sent to Spotify during the authorization. It must be one of the redirect URIs of
+// the Spotify app of the client ID, and it points to the same address the callback server listens on
+func CallbackURL() string {
+	return fmt.Sprintf("http://%s%s", CallbackAddress(), CallbackPath())
+}
+
+// LoginTimeout is how long the login waits for the browser to be redirected to the callback, it can be changed with
+// the login_timeout setting or the PLAYLISTIFY_LOGIN_TIMEOUT environment variable using durations like "90s" or "10m"
+func LoginTimeout() (time.Duration, error) {
+	value := getSetting("login_timeout", "PLAYLISTIFY_LOGIN_TIMEOUT", DefaultLoginTimeout)
+	timeout, err := time.ParseDuration(value)
+
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf(InvalidLoginTimeoutError, value)
+	}
+
+	return timeout, nil
+}
+
+// CallbackAddress is the address the authorization callback server listens on. It uses the host of the redirect URI
+// so the server isn't reachable from other machines
+func CallbackAddress() string {
+	return net.JoinHostPort(CallbackHost(), CallbackPort())
+}
+
+// CredentialStore is where the tokens are saved, it can be changed with the credential_store setting or the
+// PLAYLISTIFY_CREDENTIAL_STORE environment variable
+func CredentialStore() string {
+	return getSetting("credential_store", "PLAYLISTIFY_CREDENTIAL_STORE", AutoStore)
+}
+
+// Passphrase unlocks the encrypted credentials file. It's only read from the PLAYLISTIFY_PASSPHRASE environment
+// variable, since saving it in the configuration file would defeat the encryption
+func Passphrase() string {
+	return os.Getenv("PLAYLISTIFY_PASSPHRASE")
+}
+
+// RemoveSettings deletes the keys, which can be nested like profiles.{name}, from the configuration file. Viper
+// can't unset a key, so the file is written without them and viper is reset to forget the values set in this run
+func RemoveSettings(keys ...string) error {
+	settings := viper.AllSettings()
+	configFile := viper.ConfigFileUsed()
+
+	for _, key := range keys {
+		path := strings.Split(strings.ToLower(key), ".")
+		parent := settings
+
+		for _, segment := range path[:len(path)-1] {
+			if parent, _ = parent[segment].(map[string]interface{}); parent == nil {
+				break
+			}
+		}
+
+		delete(parent, path[len(path)-1])
+	}
+
+	content, err := json.MarshalIndent(settings, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(configFile, content, 0600); err != nil {
+		return err
+	}
+
+	viper.Reset()
+	viper.SetConfigFile(configFile)
+
+	return viper.ReadInConfig()
+}
@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestSettings(t *testing.T) {
+	tests := []struct {
+		name        string
+		setting     func() string
+		key         string
+		environment string
+		value       string
+		want        string
+	}{
+		{"default client id", ClientId, "client_id", "", "", DefaultClientId},
+		{"client id setting", ClientId, "client_id", "", "setting-id", "setting-id"},
+		{"client id environment variable", ClientId, "client_id", "env-id", "setting-id", "env-id"},
+		{"default callback path", CallbackPath, "callback_path", "", "", AuthorizationCallbackEndpoint},
+		{"callback path without slash", CallbackPath, "callback_path", "", "spotify/callback", "/spotify/callback"},
+		{"callback path with slash", CallbackPath, "callback_path", "/cb", "", "/cb"},
+		{"api base url with slash", APIBaseURL, "api_base_url", "http://localhost:9000/v1/", "", "http://localhost:9000/v1"},
+	}
+
+	environmentVariables := map[string]string{
+		"client_id":     "PLAYLISTIFY_CLIENT_ID",
+		"callback_path": "PLAYLISTIFY_CALLBACK_PATH",
+		"api_base_url":  "PLAYLISTIFY_API_BASE_URL",
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv(environmentVariables[test.key], test.environment)
+			viper.Set(test.key, test.value)
+			t.Cleanup(func() {
+				viper.Set(test.key, nil)
+			})
+
+			if got := test.setting(); got != test.want {
+				t.Errorf("the %s is %q, want %q", test.key, got, test.want)
+			}
+		})
+	}
+}
+
+func TestCallbackURL(t *testing.T) {
+	t.Setenv("PLAYLISTIFY_CALLBACK_HOST", "127.0.0.1")
+	t.Setenv("PLAYLISTIFY_CALLBACK_PORT", "9000")
+	t.Setenv("PLAYLISTIFY_CALLBACK_PATH", "cb")
+
+	if got := CallbackURL(); got != "http://127.0.0.1:9000/cb" {
+		t.Errorf("CallbackURL() = %q", got)
+	}
+
+	if got := CallbackAddress(); got != "127.0.0.1:9000" {
+		t.Errorf("CallbackAddress() = %q", got)
+	}
+
+	t.Setenv("PLAYLISTIFY_CALLBACK_HOST", "::1")
+
+	if got := CallbackURL(); got != "http://[::1]:9000/cb" {
+		t.Errorf("CallbackURL() with an IPv6 host = %q", got)
+	}
+}
+
+func TestRemoveSettings(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), ".playlistify.json")
+	content := `{"client_id": "id", "profiles": {"default": {"token": "token", "user_id": "user"}}}`
+
+	if err := os.WriteFile(fileName, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	viper.SetConfigFile(fileName)
+	t.Cleanup(viper.Reset)
+
+	if err := viper.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RemoveSettings("client_id", "profiles.default.token", "profiles.other.token"); err != nil {
+		t.Fatalf("RemoveSettings() error = %v", err)
+	}
+
+	if viper.InConfig("client_id") || viper.GetString("profiles.default.token") != "" || viper.GetString("profiles.default.user_id") != "user" {
+		t.Errorf("the settings after RemoveSettings() are %v", viper.AllSettings())
+	}
+}
+
+func TestLoginTimeout(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 5 * time.Minute, false},
+		{"90s", 90 * time.Second, false},
+		{"10m", 10 * time.Minute, false},
+		{"10", 0, true},
+		{"0s", 0, true},
+		{"-1m", 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			t.Setenv("PLAYLISTIFY_LOGIN_TIMEOUT", test.value)
+			got, err := LoginTimeout()
+
+			if got != test.want || (err != nil) != test.wantErr {
+				t.Errorf("LoginTimeout() = %v, %v, want %v, error %v", got, err, test.want, test.wantErr)
+			}
+
+			if err != nil && err.Error() != fmt.Sprintf(InvalidLoginTimeoutError, test.value) {
+				t.Errorf("LoginTimeout() error = %q", err)
+			}
+		})
+	}
+}
@@ -4,26 +4,159 @@ import "github.com/charmbracelet/lipgloss"
 
 const (
 	// Errors
-	NotLoggedInError        = `you are not logged in, please run "playlistify login"`
-	AlreadyLoggedInError    = "you are already logged in as %s (%s)"
-	NotAuthorizedError      = "you are not authorized"
-	ExpiredTokenError       = "the authentication token has expired"
-	InexistentPlaylistError = "playlist with ID of %s doesn't exist"
-	NotLoggedInCode         = 0
-	ExpiredTokenCode        = 1
-	AlreadyLoggedInCode     = 2
+	NotLoggedInError              = `you are not logged in, please run "playlistify login"`
+	AlreadyLoggedInError          = "you are already logged in as %s (%s)"
+	NotAuthorizedError            = "you are not authorized"
+	ExpiredTokenError             = "the authentication token has expired"
+	InexistentPlaylistError       = "playlist with ID of %s doesn't exist"
+	InvalidPlaylistReferenceError = "%q is not a playlist index, Spotify ID, URI, URL or the name of one of your playlists"
+	AmbiguousPlaylistError        = "%q matches several playlists: %s. Use the playlist index or ID instead"
+	TokenRequestError             = "could not get the authentication token: %s (%s)"
+	SearchFailedError             = "none of the tracks of the playlist could be fetched: %s"
+	PartialSearchWarning          = "pages %s of the playlist couldn't be fetched, the results may be incomplete"
+	PartialAllSearchWarning       = "some tracks of %s couldn't be fetched, the results may be incomplete"
+	FetchTracksError              = "could not fetch the tracks of %s: %s"
+	DuplicatesTitle               = "Duplicates in %s: %d groups"
+	CacheStatsTitle               = "%d cached playlists, %d tracks, %s in %s"
+	CheckTitle                    = "Checked against %s: %d found, %d not found, %d ambiguous"
+	DiffTitle                     = "A: %s, B: %s. %d only in A, %d only in B, %d in both"
+	NoDuplicatesTitle             = "There are no duplicates in %s"
+	DedupeDryRunTitle             = "Dry run: %d duplicates would be removed from %s, use --dry-run=false to remove them"
+	DedupeConfirmationTitle       = "%d duplicates will be removed from %s, the first occurrence of every group is kept"
+	DedupeTitle                   = `Removed %d duplicates from %s, run "playlistify undo %s" to restore them`
+	UndoConfirmationTitle         = "Operation %s (%s on %s) will be undone: %d tracks restored, %d tracks removed"
+	UndoTitle                     = "Undid operation %s on %s, recorded as operation %s"
+	HistoryTitle                  = "%d operations"
+	TokensMigratedMessage         = "Your Spotify tokens were moved to the credential store and removed from %s"
+	TokensRemovedMessage          = "Your Spotify tokens were removed from %s, the ones of the credential store are used instead"
+	PlaylistSavedMessage          = `Created the private playlist %s with %d tracks, run "playlistify undo %s" to remove them`
+	NothingToAddTitle             = "Nothing will be added to %s, all the tracks look like duplicates (use --force to add them anyway)"
+	AddConfirmationTitle          = "%d tracks will be added to %s, %d skipped because they look like duplicates"
+	AddTitle                      = `Added %d tracks to %s, run "playlistify undo %s" to remove them`
+	ExistingTrackNote             = "like #%d %s (%s)"
+	RepeatedEntryNote             = `like "%s" (%s)`
+	NothingToMergeTitle           = "Nothing will be added to %s, all the %d tracks of the sources were skipped"
+	MergeConfirmationTitle        = "%d tracks will be added to %s from %d playlists, %d skipped"
+	MergeNewConfirmationTitle     = "The private playlist %s will be created with %d tracks from %d playlists, %d skipped"
+	MergeTitle                    = `Added %d tracks to %s, %d skipped. Run "playlistify undo %s" to remove them`
+	ProfilesTitle                 = "%d profiles, the active one is %s"
+	MergeDuplicateNote            = "already in %s as #%d %s (%s)"
+	InvalidOutputError            = "invalid output format %q, use json, csv, tsv or table"
+	InvalidExportFormatError      = "invalid export format %q, use csv, json, m3u8 or xspf"
+	MissingSearchFlagsError       = "the --playlist (or --all) and --term flags are required when using --output"
+	IncompleteSearchFlagsError    = "the --playlist and --term flags must be used together"
+	NotEditablePlaylistError      = "%s can't be modified, only your own and collaborative playlists can be changed"
+	MissingSnapshotError          = "could not get the current snapshot of %s, try again later"
+	MissingModifyScopeError       = `Spotify rejected the change, please run "playlistify logout" and "playlistify login" to allow Playlistify to modify your playlists`
+	ModifyPlaylistError           = "could not modify %s: %s"
+	PartialRemovalError           = "%s. Only %d of %d tracks were removed, recorded as operation %s"
+	JournalError                  = "%d tracks were removed from %s but the operation could not be recorded: %s"
+	ConfirmationRequiredError     = "the --yes flag is required to make changes when using --output"
+	PlaylistChangedError          = "%s changed after operation %s, use --force to undo it anyway"
+	NothingToUndoError            = "there are no operations to undo"
+	UnknownOperationError         = "operation %s doesn't exist, run \"playlistify history\" to see the operations"
+	AlreadyUndoneError            = "operation %s was already undone by operation %s"
+	PartialUndoError              = "%s. Operation %s was only partially undone, recorded as operation %s"
+	UndoJournalError              = "operation %s was undone but the undo could not be recorded: %s"
+	NoTracksToSaveError           = "there are no tracks to save in the new playlist"
+	CreatePlaylistError           = "could not create the playlist %s: %s"
+	PartialSaveError              = "the playlist %s was created but only %d of %d tracks could be added: %s"
+	SaveJournalError              = "the playlist %s was created but the operation could not be recorded: %s"
+	MissingSaveAsFlagsError       = "the --playlist (or --all) and --term flags are required when using --save-as"
+	InvalidPositionError          = "invalid position %d, it must be between 1 and %d"
+	TracksNotFoundError           = `could not find "%s" in Spotify`
+	UnexpectedResponseError       = "unexpected response from Spotify"
+	PartialAddError               = "%s. Only %d of %d tracks were added, recorded as operation %s"
+	AddJournalError               = "%d tracks were added to %s but the operation could not be recorded: %s"
+	MergeIntoSourceError          = "%s can't be both a source and the target of the merge"
+	InvalidCredentialStoreError   = "invalid credential store %q, use auto, keyring, file or plain"
+	KeyringUnavailableError       = `the system keyring is not available: %s. Set "credential_store" to "file" to use an encrypted file instead`
+	CredentialsError              = "could not access the credentials: %s"
+	MissingPassphraseError        = "the credentials are encrypted, set the PLAYLISTIFY_PASSPHRASE environment variable to unlock them"
+	WrongPassphraseError          = "could not decrypt the credentials, the passphrase is wrong"
+	EmptyPassphraseError          = "the passphrase can not be empty"
+	PassphraseMismatchError       = "the passphrases do not match"
+	InvalidProfileError           = "invalid profile name %q, use lowercase letters, numbers, dashes and underscores"
+	UnknownProfileError           = `profile %s does not exist, run "playlistify login --profile %s" to create it`
+	RemoveDefaultProfileError     = `the default profile can not be removed, run "playlistify logout" to log out from it`
+	InvalidCallbackError          = "paste the whole URL you were redirected to, or the code parameter of that URL"
+	AuthorizationDeniedError      = "the authorization was denied: %s"
+	StateMismatchError            = "the state of the URL does not match this login, paste the URL you were redirected to after opening the link above"
+	CallbackListenError           = `could not listen for the authorization callback on %s: %s. Change "callback_port" (and the redirect URI of your Spotify app) or use "playlistify login --no-browser"`
+	CallbackStateMismatchError    = "the state of the callback does not match this login, it may come from an older login attempt"
+	LoginTimeoutError             = "the login timed out after %s without an authorization, run \"playlistify login\" again"
+	LoginCancelledError           = "the login was cancelled"
+	InvalidLoginTimeoutError      = "invalid login timeout %q, use a duration like 90s or 10m"
+	NotLoggedInCode               = 0
+	ExpiredTokenCode              = 1
+	AlreadyLoggedInCode           = 2
+	CredentialsErrorCode          = 3
+	// Exit codes
+	ExitCodeError          = 1
+	ExitCodeNotLoggedIn    = 2
+	ExitCodePartialResults = 3
 	// General
-	ClientId                      = "c4ab33f93b55422bb1cf39494023da7d"
+	DefaultClientId               = "c4ab33f93b55422bb1cf39494023da7d"
 	SpotifyAccountBaseURL         = "https://accounts.spotify.com"
 	SpotifyAPIBaseURL             = "https://api.spotify.com/v1"
-	AuthorizationBaseURL          = "http://localhost"
-	AuthorizationPort             = ":1024"
+	AuthorizationHost             = "localhost"
+	AuthorizationPort             = "1024"
 	AuthorizationCallbackEndpoint = "/callback"
-	AuthorizationCallbackURL      = AuthorizationBaseURL + AuthorizationPort + AuthorizationCallbackEndpoint
 	LetterRunes                   = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ-_"
-	PlaylistifyScopes             = "playlist-read-private playlist-read-collaborative user-read-email user-read-private"
+	PlaylistifyScopes             = "playlist-read-private playlist-read-collaborative playlist-modify-public playlist-modify-private user-read-email user-read-private"
 	TracksLimit                   = 50
+	ModifyTracksLimit             = 100
+	TracksByIdLimit               = 50
+	JournalFile                   = "journal.json"
+	CredentialsFile               = "credentials.enc"
+	KeyringService                = "playlistify"
+	KeyringDefaultUser            = "spotify"
+	DefaultProfile                = "default"
+	TrackFields                   = "items(added_at,track(name,id,uri,duration_ms,album(name),artists(name,id),external_ids(isrc)))"
+	DefaultMaxRetries             = 3
+	DefaultConcurrency            = 4
+	DefaultLoginTimeout           = "5m"
+	DebugLogFile                  = "playlistify-debug.log"
 	SearchingText                 = "Searching..."
+	AllPlaylistsName              = "All playlists"
+	PlaylistDescription           = "Created with Playlistify"
+	SavingPlaylistText            = "Creating the playlist..."
+	// Duplicate reasons
+	FirstOccurrenceReason = "first occurrence"
+	SameTrackReason       = "same track"
+	SameIsrcReason        = "same ISRC"
+	SimilarTrackReason    = "similar name and artist (%.2f)"
+	LocalFileReason       = "local file"
+	// Check statuses
+	FoundStatus     = "found"
+	NotFoundStatus  = "not found"
+	AmbiguousStatus = "ambiguous"
+	// Diff statuses
+	OnlyInAStatus = "only in A"
+	OnlyInBStatus = "only in B"
+	InBothStatus  = "in both"
+	// Change actions
+	KeepAction     = "keep"
+	RemoveAction   = "remove"
+	RemovedAction  = "removed"
+	RestoreAction  = "restore"
+	RestoredAction = "restored"
+	AddAction      = "add"
+	AddedAction    = "added"
+	SkipAction     = "skip"
+	// Credential stores
+	AutoStore    = "auto"
+	KeyringStore = "keyring"
+	FileStore    = "file"
+	PlainStore   = "plain"
+	// Command annotations
+	OfflineAnnotation = "offline"
+	// Journal operations
+	DedupeOperation = "dedupe"
+	UndoOperation   = "undo"
+	CreateOperation = "create"
+	AddOperation    = "add"
+	MergeOperation  = "merge"
 	// TUI Colors
 	ColorSpotifyGreen       = "#1DB954"
 	ColorSpotifyGreenOpaque = "#1DB9544D"
@@ -33,11 +166,36 @@ const (
 	ErrorState   = "error"
 	SuccessState = "success"
 	InputState   = "input"
+	// Search statuses
+	SearchComplete = "complete"
+	SearchPartial  = "partial"
+	SearchFailed   = "failed"
 	// TUI Tables
 	PlaylistsTable   = "PLAYLISTS"
 	SongsTable       = "SONGS"
+	AllSongsTable    = "ALL SONGS"
+	DuplicatesTable  = "DUPLICATES"
+	CacheTable       = "CACHE"
+	CheckTable       = "CHECK"
+	DiffTable        = "DIFF"
+	DedupeTable      = "DEDUPE"
+	UndoTable        = "UNDO"
+	HistoryTable     = "HISTORY"
+	AddTable         = "ADD"
+	MergeTable       = "MERGE"
+	ProfilesTable    = "PROFILES"
 	TableModeDefault = "table"
 	TableModeText    = "text"
+	// Output formats
+	OutputJSON  = "json"
+	OutputCSV   = "csv"
+	OutputTSV   = "tsv"
+	OutputTable = "table"
+	// Export formats
+	ExportCSV  = "csv"
+	ExportJSON = "json"
+	ExportM3U8 = "m3u8"
+	ExportXSPF = "xspf"
 )
 
 var ErrorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(ColorSpotifyRed)).Render
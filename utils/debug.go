@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// Debugf logs the message only when the debug mode is enabled with the --debug flag
+func Debugf(format string, values ...interface{}) {
+	if Options.Debug {
+		log.Printf(format, values...)
+	}
+}
+
+// DebugLogPath is the file the debug information of the profile is written to. Like the tracks cache, it's kept in
+// the user cache directory instead of the directory the command runs in
+func DebugLogPath(profile string) (string, error) {
+	directory, err := os.UserCacheDir()
+
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(ProfileDirectory(filepath.Join(directory, "playlistify"), profile), DebugLogFile), nil
+}
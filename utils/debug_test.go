@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDebugLogPath(t *testing.T) {
+	cacheDirectory := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheDirectory)
+
+	tests := []struct {
+		profile string
+		want    string
+	}{
+		{DefaultProfile, filepath.Join(cacheDirectory, "playlistify", DebugLogFile)},
+		{"work", filepath.Join(cacheDirectory, "playlistify", "profiles", "work", DebugLogFile)},
+	}
+
+	for _, test := range tests {
+		if got, err := DebugLogPath(test.profile); err != nil || got != test.want {
+			t.Errorf("DebugLogPath(%q) = %q, %v, want %q", test.profile, got, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// CommandError is an error returned by a command that has to exit with a specific code
+type CommandError struct {
+	err  error
+	code int
+}
+
+// ExitWithError prints the error to the standard error and exits with the given code. It's used by the
+// non-interactive output modes, the TUI shows the errors by itself
+func ExitWithError(err error, code int) {
+	fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+	os.Exit(code)
+}
+
+// NewCommandError sets the exit code of an error returned by a command, instead of exiting right away like
+// ExitWithError does
+func NewCommandError(err error, code int) error {
+	return &CommandError{err, code}
+}
+
+// GetExitCode returns the exit code of an error returned by a command, which is ExitCodeError unless it's a
+// CommandError
+func GetExitCode(err error) int {
+	var commandError *CommandError
+
+	if errors.As(err, &commandError) {
+		return commandError.code
+	}
+
+	return ExitCodeError
+}
+
+func (err *CommandError) Error() string {
+	return err.err.Error()
+}
+
+func (err *CommandError) Unwrap() error {
+	return err.err
+}
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestGetExitCode(t *testing.T) {
+	commandError := NewCommandError(errors.New("not logged in"), ExitCodeNotLoggedIn)
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"error", errors.New("unknown flag"), ExitCodeError},
+		{"command error", commandError, ExitCodeNotLoggedIn},
+		{"wrapped command error", fmt.Errorf("could not run the command: %w", commandError), ExitCodeNotLoggedIn},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := GetExitCode(test.err); got != test.want {
+				t.Errorf("GetExitCode(%v) = %d, want %d", test.err, got, test.want)
+			}
+		})
+	}
+
+	if commandError.Error() != "not logged in" {
+		t.Errorf("the message of the command error is %q", commandError.Error())
+	}
+}
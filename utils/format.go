@@ -0,0 +1,17 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+)
+
+// FormatNumbers joins a list of numbers (like pages or positions) with commas
+func FormatNumbers(numbers []int) string {
+	var formattedNumbers []string
+
+	for _, number := range numbers {
+		formattedNumbers = append(formattedNumbers, strconv.Itoa(number))
+	}
+
+	return strings.Join(formattedNumbers, ", ")
+}
@@ -0,0 +1,19 @@
+package utils
+
+// GlobalOptions contains the values of the global flags. They are kept out of viper because every viper
+// value ends up written to the configuration file
+type GlobalOptions struct {
+	Debug       bool
+	MaxRetries  int
+	Concurrency int
+	Output      string
+	// Refresh ignores the cached tracks of the playlists
+	Refresh bool
+	// Profile overrides the active profile for a single command
+	Profile string
+}
+
+var Options = GlobalOptions{
+	MaxRetries:  DefaultMaxRetries,
+	Concurrency: DefaultConcurrency,
+}
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// profileSettings are the settings of an account, which are kept under profiles.{name} in the configuration file
+var profileSettings = []string{"token", "refresh_token", "token_expiration", "user_id", "playlists"}
+
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ActiveProfile is the profile of the --profile flag, the PLAYLISTIFY_PROFILE environment variable or the profile
+// chosen with "playlistify profile use", in that order
+func ActiveProfile() string {
+	if Options.Profile != "" {
+		return strings.ToLower(Options.Profile)
+	}
+
+	return strings.ToLower(getSetting("profile", "PLAYLISTIFY_PROFILE", DefaultProfile))
+}
+
+// ValidateProfileName checks the name can be used as a key of the configuration file and as a directory name. Viper
+// keys aren't case sensitive, so the names are always lowercase
+func ValidateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf(InvalidProfileError, name)
+	}
+
+	return nil
+}
+
+// ProfileKey is the key of a setting of the active profile
+func ProfileKey(key string) string {
+	return ProfileSettingKey(ActiveProfile(), key)
+}
+
+// ProfileSettingKey is the key of a setting of the profile
+func ProfileSettingKey(profile string, key string) string {
+	return fmt.Sprintf("profiles.%s.%s", profile, key)
+}
+
+// ProfileDirectory is the directory inside the base directory where the files of the profile are kept. The default
+// profile uses the base directory itself, so the files written before the profiles existed are still used
+func ProfileDirectory(base string, profile string) string {
+	if profile == DefaultProfile {
+		return base
+	}
+
+	return filepath.Join(base, "profiles", profile)
+}
+
+// KeyringUser is the keyring entry of the profile's credentials
+func KeyringUser(profile string) string {
+	if profile == DefaultProfile {
+		return KeyringDefaultUser
+	}
+
+	return KeyringDefaultUser + ":" + profile
+}
+
+// GetProfiles returns the names of the profiles of the configuration file
+func GetProfiles() []string {
+	var profiles []string
+
+	for name := range viper.GetStringMap("profiles") {
+		profiles = append(profiles, name)
+	}
+
+	return profiles
+}
+
+// MigrateSettings moves the settings written before the profiles existed to the default profile
+func MigrateSettings() error {
+	var legacyKeys []string
+
+	for _, key := range profileSettings {
+		if viper.InConfig(key) {
+			viper.Set(ProfileSettingKey(DefaultProfile, key), viper.Get(key))
+			legacyKeys = append(legacyKeys, key)
+		}
+	}
+
+	if len(legacyKeys) == 0 {
+		return nil
+	}
+
+	return RemoveSettings(legacyKeys...)
+}
@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestValidateProfileName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"work", false},
+		{"my-work_2", false},
+		{"2nd", false},
+		{"", true},
+		{"Work", true},
+		{"-work", true},
+		{"my work", true},
+		{"work.old", true},
+		{"../work", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := ValidateProfileName(test.name); (err != nil) != test.wantErr {
+				t.Errorf("ValidateProfileName(%q) error = %v, want error %v", test.name, err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestActiveProfile(t *testing.T) {
+	previous := Options.Profile
+
+	t.Cleanup(func() {
+		Options.Profile = previous
+		viper.Set("profile", nil)
+	})
+
+	tests := []struct {
+		name        string
+		flag        string
+		environment string
+		setting     string
+		want        string
+	}{
+		{"default", "", "", "", DefaultProfile},
+		{"setting", "", "", "home", "home"},
+		{"environment variable", "", "Work", "home", "work"},
+		{"flag", "test", "work", "home", "test"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			Options.Profile = test.flag
+			t.Setenv("PLAYLISTIFY_PROFILE", test.environment)
+			viper.Set("profile", test.setting)
+
+			if got := ActiveProfile(); got != test.want {
+				t.Errorf("ActiveProfile() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestProfileDirectoryAndKeyringUser(t *testing.T) {
+	if got := ProfileDirectory("base", DefaultProfile); got != "base" {
+		t.Errorf("ProfileDirectory() of the default profile = %q, want the base directory", got)
+	}
+
+	if got := ProfileDirectory("base", "work"); got != filepath.Join("base", "profiles", "work") {
+		t.Errorf("ProfileDirectory() of the work profile = %q", got)
+	}
+
+	if got := KeyringUser(DefaultProfile); got != KeyringDefaultUser {
+		t.Errorf("KeyringUser() of the default profile = %q, want %q", got, KeyringDefaultUser)
+	}
+
+	if got := KeyringUser("work"); got != KeyringDefaultUser+":work" {
+		t.Errorf("KeyringUser() of the work profile = %q", got)
+	}
+}
+
+func TestMigrateSettings(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), ".playlistify.json")
+	content := `{"user_id": "user", "token": "token", "playlists": [{"id": "p1"}], "max_retries": "3"}`
+
+	if err := os.WriteFile(fileName, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	viper.SetConfigFile(fileName)
+	t.Cleanup(viper.Reset)
+
+	if err := viper.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := MigrateSettings(); err != nil {
+		t.Fatalf("MigrateSettings() error = %v", err)
+	}
+
+	// The file is read again, so the settings are checked in the file and not only in memory
+	if err := viper.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"user_id", "token", "playlists"} {
+		if viper.InConfig(key) {
+			t.Errorf("the setting %s is still at the top level", key)
+		}
+	}
+
+	if viper.GetString(ProfileSettingKey(DefaultProfile, "user_id")) != "user" || viper.GetString(ProfileSettingKey(DefaultProfile, "token")) != "token" {
+		t.Errorf("the settings weren't moved to the default profile: %v", viper.AllSettings())
+	}
+
+	if viper.GetString("max_retries") != "3" {
+		t.Errorf("the max_retries setting was changed to %q", viper.GetString("max_retries"))
+	}
+
+	if err := MigrateSettings(); err != nil {
+		t.Errorf("MigrateSettings() without legacy settings error = %v", err)
+	}
+}